@@ -0,0 +1,68 @@
+// reads Engine/Build/Build.version, which tells the exact version of an engine installation.
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+
+	"unreal-plugin-release/model"
+)
+
+/*
+Reads the exact version of the engine installed at the root.
+*/
+func readEngineBuildVersion(fsys FileSystem, engineRoot string) (*model.EngineBuildVersion, error) {
+	data, err := fsys.ReadFile(filepath.Join(engineRoot, "Engine", "Build", "Build.version"))
+	if err != nil {
+		return nil, err
+	}
+
+	buildVersion := model.EngineBuildVersion{}
+	if err := json.Unmarshal(data, &buildVersion); err != nil {
+		return nil, fmt.Errorf("failed to parse Build.version of %s: %w", engineRoot, err)
+	}
+	return &buildVersion, nil
+}
+
+/*
+Makes sure the engine resolved for the requested version really is that version, returning its exact version.
+An engine without a Build.version is trusted, and nil is returned.
+*/
+func (pb *PluginBuilder) verifyEngineIdentity(version string) (*model.EngineBuildVersion, error) {
+	engine := pb.engines.Resolve(version)
+	buildVersion, err := readEngineBuildVersion(pb.fs, engine.Root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	requested, err := parseEngineVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if buildVersion.MajorVersion != requested[0] || buildVersion.MinorVersion != requested[1] {
+		return nil, fmt.Errorf("the engine in %s is UE %s, not %s", engine.Root, formatEngineBuildVersion(buildVersion), version)
+	}
+	return buildVersion, nil
+}
+
+/*
+The exact version of the verified engine, like 5.3.2, or the requested version with a zero patch for an engine without a Build.version.
+*/
+func exactEngineVersion(version string, buildVersion *model.EngineBuildVersion) string {
+	if buildVersion == nil {
+		return version + ".0"
+	}
+	return formatEngineBuildVersion(buildVersion)
+}
+
+func formatEngineBuildVersion(buildVersion *model.EngineBuildVersion) string {
+	return strconv.Itoa(buildVersion.MajorVersion) + "." + strconv.Itoa(buildVersion.MinorVersion) + "." + strconv.Itoa(buildVersion.PatchVersion)
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+const testBuildVersion = `{
+	"MajorVersion": 5,
+	"MinorVersion": 3,
+	"PatchVersion": 2,
+	"Changelist": 29314046,
+	"CompatibleChangelist": 27405482,
+	"IsLicenseeVersion": 0,
+	"IsPromotedBuild": 1,
+	"BranchName": "++UE5+Release-5.3"
+}`
+
+func TestVerifyEngineIdentityShouldReturnExactVersion(t *testing.T) {
+	// given
+	base := t.TempDir()
+	writeBuildVersion(filepath.Join(base, "UE_5.3"), testBuildVersion, t)
+	config := model.Config{EngineBaseDirectory: base, BuildScriptPath: "RunUAT.bat"}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	actual, err := underTest.verifyEngineIdentity("5.3")
+
+	// then
+	if err != nil || actual == nil {
+		t.Fatalf("A matching engine should be verified, got %v", err)
+	}
+	if formatEngineBuildVersion(actual) != "5.3.2" || actual.Changelist != 29314046 {
+		t.Errorf("Unexpected build version: %+v", actual)
+	}
+	if exactEngineVersion("5.3", actual) != "5.3.2" {
+		t.Errorf("Expected the exact version 5.3.2, got %q", exactEngineVersion("5.3", actual))
+	}
+}
+
+func TestVerifyEngineIdentityShouldRefuseMismatch(t *testing.T) {
+	// given
+	base := t.TempDir()
+	writeBuildVersion(filepath.Join(base, "UE_5.4"), testBuildVersion, t)
+	config := model.Config{EngineBaseDirectory: base, BuildScriptPath: "RunUAT.bat"}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	_, err := underTest.verifyEngineIdentity("5.4")
+
+	// then
+	if err == nil {
+		t.Error("A 5.3 engine in the UE_5.4 folder must be refused.")
+	}
+}
+
+func TestVerifyEngineIdentityShouldTrustEngineWithoutBuildVersion(t *testing.T) {
+	// given
+	base := t.TempDir()
+	makeDir(base, "UE_5.4", t)
+	config := model.Config{EngineBaseDirectory: base, BuildScriptPath: "RunUAT.bat"}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	actual, err := underTest.verifyEngineIdentity("5.4")
+
+	// then
+	if err != nil || actual != nil {
+		t.Errorf("An engine without Build.version should be trusted, got %+v (%v)", actual, err)
+	}
+	if exactEngineVersion("5.4", actual) != "5.4.0" {
+		t.Errorf("Expected the fallback version 5.4.0, got %q", exactEngineVersion("5.4", actual))
+	}
+}
+
+func writeBuildVersion(engineRoot string, contents string, t *testing.T) {
+	t.Helper()
+	dir := makeDir(engineRoot, filepath.Join("Engine", "Build"), t)
+	if err := os.WriteFile(filepath.Join(dir, "Build.version"), []byte(contents), 0644); err != nil {
+		t.Fatal("Failed to write Build.version")
+	}
+}
@@ -0,0 +1,38 @@
+// writes checksums next to the archives, so uploads can be verified.
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+)
+
+const checksumFileExtension = ".sha256"
+
+/*
+Writes the SHA-256 of the file next to it, in the format sha256sum -c accepts.
+*/
+func writeChecksumFile(fsys FileSystem, path string) error {
+	checksum, err := sha256OfFile(fsys, path)
+	if err != nil {
+		return err
+	}
+
+	line := checksum + "  " + filepath.Base(path) + "\n"
+	return fsys.WriteFile(path+checksumFileExtension, []byte(line), 0644)
+}
+
+func sha256OfFile(fsys FileSystem, path string) (string, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
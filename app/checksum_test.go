@@ -0,0 +1,28 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteChecksumFileShouldWriteSha256SumLine(t *testing.T) {
+	// given
+	archive := filepath.Join(t.TempDir(), "MyPlugin_5.4.zip")
+	if err := os.WriteFile(archive, []byte("abc"), 0644); err != nil {
+		t.Fatal("Failed to write archive")
+	}
+
+	// when
+	err := writeChecksumFile(OSFileSystem{}, archive)
+
+	// then
+	if err != nil {
+		t.Fatalf("Writing the checksum should not fail: %v", err)
+	}
+	data, _ := os.ReadFile(archive + checksumFileExtension)
+	expected := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  MyPlugin_5.4.zip\n"
+	if string(data) != expected {
+		t.Errorf("Expected %q, actual %q", expected, string(data))
+	}
+}
@@ -0,0 +1,70 @@
+// reuses a single build for plugins without code modules, whose output only differs in the descriptor per engine version.
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"unreal-plugin-release/model"
+)
+
+// the staging folder keeping the first build of a content-only plugin until every version is packaged
+const contentOnlyBuildDirectoryName = ".content-only"
+
+// a plugin without modules has nothing to compile, so its build is the same for every engine version
+func isContentOnlyPlugin(fsys FileSystem, pluginPath string) bool {
+	descriptor, err := readPluginDescriptor(fsys, pluginPath)
+	if err != nil {
+		return false
+	}
+
+	var modules []json.RawMessage
+	if _, err := descriptor.getValue("Modules", &modules); err != nil {
+		return false
+	}
+	return len(modules) == 0
+}
+
+// keeps a copy of the freshly built release, before its descriptor is stamped, for the rest of the versions
+func (pb *PluginBuilder) keepContentOnlyBuild(release *Release) error {
+	pb.contentOnlyBuild = filepath.Join(pb.makeStagingRoot(), contentOnlyBuildDirectoryName)
+	if err := copyDirectory(pb.fs, release.StagingDir, pb.contentOnlyBuild, nil); err != nil {
+		return fmt.Errorf("failed to keep the content-only build: %w", err)
+	}
+	// not from the report, a resumed batch starts with the versions of the interrupted one in it
+	pb.contentOnlyBuiltVersion = release.Version
+	pb.contentOnlyPackageOnly = release.CmdInput.PackageOnly
+	return nil
+}
+
+/*
+Copies the content-only build of an earlier version into the staging directory instead of building again.
+*/
+func (pb *PluginBuilder) reuseContentOnlyBuild(version, stagingDir string, platforms []string) error {
+	builtVersion := pb.contentOnlyBuiltVersion
+	pb.log.Info("content-only plugin, reusing an earlier build", "version", version, "builtVersion", builtVersion)
+
+	if err := copyDirectory(pb.fs, pb.contentOnlyBuild, stagingDir, nil); err != nil {
+		return fmt.Errorf("failed to copy the content-only build: %w", err)
+	}
+
+	pb.report.Versions = append(pb.report.Versions, model.VersionReport{
+		Version:         version,
+		OutputDir:       stagingDir,
+		TargetPlatforms: platforms,
+		PackageOnly:     pb.contentOnlyPackageOnly,
+		ReusedBuildOf:   builtVersion,
+		Succeeded:       true,
+	})
+	return nil
+}
+
+func (pb *PluginBuilder) discardContentOnlyBuild() {
+	if pb.contentOnlyBuild != "" {
+		pb.guard.Remove(pb.contentOnlyBuild)
+		pb.contentOnlyBuild = ""
+		pb.contentOnlyBuiltVersion = ""
+		pb.contentOnlyPackageOnly = false
+	}
+}
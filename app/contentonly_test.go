@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+const testContentOnlyDescriptor = `{
+	"FileVersion": 3,
+	"VersionName": "1.0.0",
+	"FriendlyName": "My Assets"
+}`
+
+// fake executor counting how many times the plugin was built
+type CountingExecutor struct {
+	FakeExecutor
+	builds *int
+}
+
+func (e CountingExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string, extraArgs []string) *exec.Cmd {
+	*e.builds++
+	return e.FakeExecutor.CreateBuilderCommand(buildScriptPath, pluginLocation, outputDir, extraArgs)
+}
+
+func TestIsContentOnlyPlugin(t *testing.T) {
+	// given
+	base := t.TempDir()
+	withModules := writeDescriptor(makeDir(base, "Code", t), testDescriptor, t)
+	withoutModules := writeDescriptor(makeDir(base, "Assets", t), testContentOnlyDescriptor, t)
+	withEmptyModules := writeDescriptor(makeDir(base, "Empty", t), `{"FileVersion": 3, "Modules": []}`, t)
+
+	// then
+	if isContentOnlyPlugin(OSFileSystem{}, withModules) {
+		t.Error("A plugin with modules has code to compile.")
+	}
+	if !isContentOnlyPlugin(OSFileSystem{}, withoutModules) || !isContentOnlyPlugin(OSFileSystem{}, withEmptyModules) {
+		t.Error("A plugin without modules should be content-only.")
+	}
+	if isContentOnlyPlugin(OSFileSystem{}, filepath.Join(base, "Missing.uplugin")) {
+		t.Error("An unreadable descriptor must not be treated as content-only.")
+	}
+}
+
+func TestContentOnlyPluginShouldBeBuiltOnce(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := writeTestProject(base, []string{"5.3", "5.4"}, t)
+	writeDescriptor(filepath.Dir(config.PluginPath), testContentOnlyDescriptor, t)
+	output := config.OutputBaseDirectory
+	builds := 0
+	underTest := NewPluginBuilder(config, CountingExecutor{builds: &builds})
+
+	// when
+	if err := underTest.BuildPluginsForSelectedVersions(context.Background(), model.CmdInput{EngineVersions: "5.3,5.4", SkipDocs: true}, filepath.Join(base, "script.exe")); err != nil {
+		t.Fatalf("The batch should have succeeded: %v", err)
+	}
+
+	// then
+	if builds != 1 {
+		t.Errorf("A content-only plugin should be built once, was built %d times", builds)
+	}
+	for _, version := range []string{"5.3", "5.4"} {
+		releaseDir := filepath.Join(output, "MyPlugin_"+version)
+		descriptor, err := readPluginDescriptor(OSFileSystem{}, filepath.Join(releaseDir, "MyPlugin.uplugin"))
+		var engineVersion string
+		if err == nil {
+			descriptor.getValue("EngineVersion", &engineVersion)
+		}
+		if engineVersion != version+".0" {
+			t.Errorf("The %s release should be stamped with its own engine version, got %q", version, engineVersion)
+		}
+		if !isFileExist(releaseDir + ".zip") {
+			t.Errorf("The %s archive was not published.", version)
+		}
+	}
+	if underTest.report.Versions[1].ReusedBuildOf != "5.3" {
+		t.Errorf("The report should record the reused build, got %+v", underTest.report.Versions[1])
+	}
+	if IsPathExist(filepath.Join(output, model.StagingDirectoryName)) {
+		t.Error("The shared build should not be left in the staging area.")
+	}
+}
+
+func TestReusedContentOnlyBuildShouldBeReportedFromTheBuiltVersion(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := model.Config{OutputBaseDirectory: makeDir(base, "Output", t)}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+	// a resumed batch starts with the report of the interrupted one
+	underTest.report.Versions = []model.VersionReport{{Version: "5.2", PackageOnly: true, Succeeded: true}}
+	built := &Release{Version: "5.3", StagingDir: makeDir(base, "MyPlugin_5.3", t)}
+	makeFile(built.StagingDir, "MyPlugin.uplugin", t)
+	if err := underTest.keepContentOnlyBuild(built); err != nil {
+		t.Fatalf("The build should be kept: %v", err)
+	}
+
+	// when
+	err := underTest.reuseContentOnlyBuild("5.4", filepath.Join(base, "MyPlugin_5.4"), nil)
+
+	// then
+	if err != nil {
+		t.Fatalf("The build should be reused: %v", err)
+	}
+	reused := underTest.report.Versions[len(underTest.report.Versions)-1]
+	if reused.ReusedBuildOf != "5.3" || reused.PackageOnly {
+		t.Errorf("The report should point to the build of 5.3, got %+v", reused)
+	}
+}
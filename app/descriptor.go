@@ -0,0 +1,92 @@
+// reads and stamps .uplugin descriptors, keeping the order of their fields so the diff stays readable.
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// a .uplugin file's top level fields, in the order they were read
+type pluginDescriptor struct {
+	keys   []string
+	fields map[string]json.RawMessage
+}
+
+func readPluginDescriptor(fsys FileSystem, path string) (*pluginDescriptor, error) {
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin descriptor: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errors.New("plugin descriptor " + path + " is not a json object")
+	}
+
+	descriptor := &pluginDescriptor{fields: map[string]json.RawMessage{}}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse plugin descriptor: %w", err)
+		}
+		key := token.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("failed to parse plugin descriptor field %q: %w", key, err)
+		}
+		descriptor.set(key, value)
+	}
+
+	return descriptor, nil
+}
+
+// sets the field, keeping its place if it already exists, appending it otherwise
+func (d *pluginDescriptor) set(key string, value json.RawMessage) {
+	if _, exists := d.fields[key]; !exists {
+		d.keys = append(d.keys, key)
+	}
+	d.fields[key] = value
+}
+
+func (d *pluginDescriptor) setValue(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	d.set(key, data)
+	return nil
+}
+
+// decodes the field into target, reporting whether the field exists
+func (d *pluginDescriptor) getValue(key string, target any) (bool, error) {
+	value, exists := d.fields[key]
+	if !exists {
+		return false, nil
+	}
+	return true, json.Unmarshal(value, target)
+}
+
+func (d *pluginDescriptor) write(fsys FileSystem, path string) error {
+	var compact bytes.Buffer
+	compact.WriteString("{")
+	for i, key := range d.keys {
+		if i > 0 {
+			compact.WriteString(",")
+		}
+		encodedKey, _ := json.Marshal(key)
+		compact.Write(encodedKey)
+		compact.WriteString(":")
+		compact.Write(d.fields[key])
+	}
+	compact.WriteString("}")
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, compact.Bytes(), "", "\t"); err != nil {
+		return fmt.Errorf("failed to format plugin descriptor: %w", err)
+	}
+	indented.WriteString("\n")
+	return fsys.WriteFile(path, indented.Bytes(), 0644)
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDescriptor = "\xef\xbb\xbf" + `{
+	"FileVersion": 3,
+	"VersionName": "1.4.2",
+	"FriendlyName": "My Plugin",
+	"Modules": [
+		{
+			"Name": "MyPlugin",
+			"Type": "Runtime"
+		}
+	]
+}`
+
+func TestDescriptorShouldKeepFieldOrder(t *testing.T) {
+	// given
+	path := writeDescriptor(t.TempDir(), testDescriptor, t)
+	descriptor, err := readPluginDescriptor(OSFileSystem{}, path)
+	if err != nil {
+		t.Fatalf("Failed to read descriptor: %v", err)
+	}
+
+	// when
+	descriptor.setValue("VersionName", "1.5.0")
+	descriptor.setValue("SupportedTargetPlatforms", []string{"Win64"})
+	if err := descriptor.write(OSFileSystem{}, path); err != nil {
+		t.Fatalf("Failed to write descriptor: %v", err)
+	}
+
+	// then
+	data, _ := os.ReadFile(path)
+	written := string(data)
+	order := []string{`"FileVersion"`, `"VersionName": "1.5.0"`, `"FriendlyName"`, `"Modules"`, `"SupportedTargetPlatforms"`}
+	last := -1
+	for _, field := range order {
+		index := strings.Index(written, field)
+		if index <= last {
+			t.Fatalf("Field %s is missing or out of order in:\n%s", field, written)
+		}
+		last = index
+	}
+}
+
+func TestDescriptorGetValue(t *testing.T) {
+	// given
+	descriptor, _ := readPluginDescriptor(OSFileSystem{}, writeDescriptor(t.TempDir(), testDescriptor, t))
+	var versionName string
+	var missing []string
+
+	// when
+	found, err := descriptor.getValue("VersionName", &versionName)
+	foundMissing, _ := descriptor.getValue("SupportedTargetPlatforms", &missing)
+
+	// then
+	if !found || err != nil || versionName != "1.4.2" {
+		t.Errorf("Expected VersionName 1.4.2, got %q (%v)", versionName, err)
+	}
+	if foundMissing {
+		t.Error("A missing field should not be reported as found.")
+	}
+}
+
+func TestInvalidDescriptorShouldReturnError(t *testing.T) {
+	// given
+	path := writeDescriptor(t.TempDir(), "[]", t)
+
+	// when
+	_, err := readPluginDescriptor(OSFileSystem{}, path)
+
+	// then
+	if err == nil {
+		t.Error("A descriptor that is not an object should be rejected.")
+	}
+}
+
+func writeDescriptor(dir string, contents string, t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(dir, "MyPlugin.uplugin")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal("Failed to write descriptor")
+	}
+	return path
+}
@@ -0,0 +1,259 @@
+// checks everything a batch needs, one item at a time, so a broken setup tells what exactly is wrong with it.
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// below these, the output directory may run out of space in the middle of a batch
+const (
+	lowDiskSpaceWarning = 5 << 30
+	lowDiskSpaceFailure = 1 << 30
+)
+
+// the outcome of checking a single item of the setup
+type CheckResult struct {
+	Item   string
+	Status CheckStatus
+	Detail string
+}
+
+/*
+Checks the paths of the config, the engine of every version, the output directory, the plugin descriptor and the docs.
+Without versions, the engines found by the locators are checked.
+*/
+func RunDoctor(config *model.Config, versions []string, execPath string) []CheckResult {
+	var results []CheckResult
+	results = append(results, checkEngineBaseDirectory(config)...)
+	results = append(results, checkEngines(config, versions)...)
+	results = append(results, checkOutputDirectory(config)...)
+	results = append(results, checkPlugin(config.PluginPath)...)
+	results = append(results, checkDocs(config, execPath)...)
+	results = append(results, checkSettings(config)...)
+	return results
+}
+
+func pass(item string, detail string, args ...any) CheckResult {
+	return CheckResult{Item: item, Status: CheckPass, Detail: fmt.Sprintf(detail, args...)}
+}
+
+func warn(item string, detail string, args ...any) CheckResult {
+	return CheckResult{Item: item, Status: CheckWarn, Detail: fmt.Sprintf(detail, args...)}
+}
+
+func fail(item string, detail string, args ...any) CheckResult {
+	return CheckResult{Item: item, Status: CheckFail, Detail: fmt.Sprintf(detail, args...)}
+}
+
+func checkEngineBaseDirectory(config *model.Config) []CheckResult {
+	var results []CheckResult
+	switch {
+	case config.EngineBaseDirectory == "":
+		results = append(results, pass("engineBaseDirectory", "not set, the engines come from engines and the launcher"))
+	case !isDirectory(config.EngineBaseDirectory):
+		results = append(results, fail("engineBaseDirectory", "%s is not an existing folder", config.EngineBaseDirectory))
+	case IsPathEqual(config.EngineBaseDirectory, config.OutputBaseDirectory):
+		results = append(results, fail("engineBaseDirectory", "it is the same as outputBaseDirectory, the releases would go into the engines"))
+	default:
+		results = append(results, pass("engineBaseDirectory", "%s", config.EngineBaseDirectory))
+	}
+
+	if config.BuildScriptPath == "" {
+		results = append(results, fail("buildScriptPath", "not set, it's the path of RunUAT within an engine folder"))
+	} else {
+		results = append(results, pass("buildScriptPath", "%s", config.BuildScriptPath))
+	}
+
+	if config.LauncherInstalledPath != "" {
+		if IsFile(config.LauncherInstalledPath) {
+			results = append(results, pass("launcherInstalledPath", "%s", config.LauncherInstalledPath))
+		} else {
+			results = append(results, fail("launcherInstalledPath", "%s not found", config.LauncherInstalledPath))
+		}
+	}
+
+	for _, version := range sortedKeys(config.Engines) {
+		location := config.Engines[version]
+		item := "engines." + version
+		if _, err := parseEngineVersion(version); err != nil {
+			results = append(results, fail(item, "%s is not a MAJOR.MINOR version", version))
+		} else if !IsPathExist(location) {
+			results = append(results, fail(item, "%s not found", location))
+		} else {
+			results = append(results, pass(item, "%s", location))
+		}
+	}
+	return results
+}
+
+// the build script and the Build.version of the engine of every version
+func checkEngines(config *model.Config, versions []string) []CheckResult {
+	resolver := NewEngineResolver(config)
+	resolver.log = NewDiscardLogger()
+	if len(versions) == 0 {
+		for _, engine := range resolver.FindEngines() {
+			versions = append(versions, engine.Version)
+		}
+		if len(versions) == 0 {
+			return []CheckResult{warn("engines", "no engines found, pass --engine-versions or set engineBaseDirectory or engines")}
+		}
+	}
+
+	var results []CheckResult
+	for _, version := range versions {
+		item := "UE " + version
+		engine := resolver.Resolve(version)
+		if !IsFile(engine.BuildScriptPath) {
+			results = append(results, fail(item, "build script %s not found", engine.BuildScriptPath))
+			continue
+		}
+
+		buildVersion, err := readEngineBuildVersion(OSFileSystem{}, engine.Root)
+		requested, parseErr := parseEngineVersion(version)
+		switch {
+		case parseErr != nil:
+			results = append(results, fail(item, "%s is not a MAJOR.MINOR version", version))
+		case os.IsNotExist(err):
+			results = append(results, warn(item, "%s has no Build.version, its version can't be verified", engine.Root))
+		case err != nil:
+			results = append(results, fail(item, "%v", err))
+		case buildVersion.MajorVersion != requested[0] || buildVersion.MinorVersion != requested[1]:
+			results = append(results, fail(item, "the engine in %s is UE %s", engine.Root, formatEngineBuildVersion(buildVersion)))
+		default:
+			results = append(results, pass(item, "UE %s in %s (from %s)", formatEngineBuildVersion(buildVersion), engine.Root, engine.Source))
+		}
+	}
+	return results
+}
+
+// exists, can be written, and has room for the releases
+func checkOutputDirectory(config *model.Config) []CheckResult {
+	const item = "outputBaseDirectory"
+	if config.OutputBaseDirectory == "" {
+		return []CheckResult{fail(item, "not set")}
+	}
+	if !isDirectory(config.OutputBaseDirectory) {
+		return []CheckResult{fail(item, "%s is not an existing folder", config.OutputBaseDirectory)}
+	}
+
+	results := []CheckResult{pass(item, "%s", config.OutputBaseDirectory)}
+	probe, err := os.CreateTemp(config.OutputBaseDirectory, ".unreal-plugin-release-doctor-")
+	if err != nil {
+		results = append(results, fail("output write permission", "can't write to %s: %v", config.OutputBaseDirectory, err))
+	} else {
+		probe.Close()
+		os.Remove(probe.Name())
+		results = append(results, pass("output write permission", "%s can be written", config.OutputBaseDirectory))
+	}
+
+	free, err := freeDiskSpace(config.OutputBaseDirectory)
+	switch {
+	case err != nil:
+		results = append(results, warn("output disk space", "can't tell the free space: %v", err))
+	case free < lowDiskSpaceFailure:
+		results = append(results, fail("output disk space", "only %s free, a release needs more", formatBytes(free)))
+	case free < lowDiskSpaceWarning:
+		results = append(results, warn("output disk space", "only %s free, it may run out during a batch", formatBytes(free)))
+	default:
+		results = append(results, pass("output disk space", "%s free", formatBytes(free)))
+	}
+	return results
+}
+
+// the descriptor exists and parses, so UAT and the stamping can read it
+func checkPlugin(pluginPath string) []CheckResult {
+	const item = "pluginPath"
+	switch {
+	case pluginPath == "":
+		return []CheckResult{fail(item, "not set, it's the path of the .uplugin file")}
+	case !IsPathExist(pluginPath):
+		return []CheckResult{fail(item, "%s not found", pluginPath)}
+	case !IsFile(pluginPath):
+		return []CheckResult{fail(item, "%s is a folder, it must point to the .uplugin file", pluginPath)}
+	}
+
+	results := []CheckResult{pass(item, "%s", pluginPath)}
+	descriptor, err := readPluginDescriptor(OSFileSystem{}, pluginPath)
+	if err != nil {
+		return append(results, fail("plugin descriptor", "%v", err))
+	}
+
+	var versionName string
+	descriptor.getValue("VersionName", &versionName)
+	if versionName == "" {
+		return append(results, warn("plugin descriptor", "parsed, but has no VersionName"))
+	}
+	return append(results, pass("plugin descriptor", "parsed, VersionName %s", versionName))
+}
+
+// docs need FilterPlugin.ini next to the executable, telling where they go inside the plugin
+func checkDocs(config *model.Config, execPath string) []CheckResult {
+	iniPath := GetFullPathForFileInExecDir(execPath, model.PluginConfigurationIniFileName)
+	if config.DocsPath == "" {
+		if IsFile(iniPath) {
+			return []CheckResult{warn("docsPath", "not set, so %s is not used", iniPath)}
+		}
+		return []CheckResult{pass("docsPath", "not set, the releases have no docs")}
+	}
+
+	var results []CheckResult
+	if IsFile(config.DocsPath) {
+		results = append(results, pass("docsPath", "%s", config.DocsPath))
+	} else {
+		results = append(results, fail("docsPath", "%s not found", config.DocsPath))
+	}
+
+	docsPathInPlugin, err := readDocsPathInPlugin(OSFileSystem{}, iniPath)
+	switch {
+	case err != nil:
+		results = append(results, fail(model.PluginConfigurationIniFileName, "%v", err))
+	case !strings.HasPrefix(docsPathInPlugin, "/"):
+		results = append(results, fail(model.PluginConfigurationIniFileName, "the docs path on its second line must start with /, like /Docs/MyPlugin.pdf, got %q", docsPathInPlugin))
+	case !strings.EqualFold(filepath.Ext(docsPathInPlugin), filepath.Ext(config.DocsPath)):
+		results = append(results, warn(model.PluginConfigurationIniFileName, "the docs go to %s, but docsPath is a %s file", docsPathInPlugin, filepath.Ext(config.DocsPath)))
+	default:
+		results = append(results, pass(model.PluginConfigurationIniFileName, "the docs go to %s", docsPathInPlugin))
+	}
+	return results
+}
+
+// the settings that are validated before a batch
+func checkSettings(config *model.Config) []CheckResult {
+	var results []CheckResult
+	for _, problem := range ValidateSettings(config) {
+		results = append(results, fail(problem.Field, "%s", problem.Error()))
+	}
+	return results
+}
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func formatBytes(bytes uint64) string {
+	return fmt.Sprintf("%.1f GiB", float64(bytes)/(1<<30))
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestDoctorShouldPassAWorkingSetup(t *testing.T) {
+	// given
+	config, execPath := writeDoctorSetup(t.TempDir(), t)
+
+	// when
+	results := RunDoctor(config, []string{"5.4"}, execPath)
+
+	// then
+	for _, result := range results {
+		// the free space depends on the machine running the tests
+		if result.Status == CheckFail && result.Item != "output disk space" {
+			t.Errorf("%s should not fail: %s", result.Item, result.Detail)
+		}
+	}
+	if status := statusOf(results, "UE 5.4"); status != CheckPass {
+		t.Errorf("The engine with a matching Build.version should pass, got %q", status)
+	}
+}
+
+func TestDoctorShouldFailEveryBrokenItemSeparately(t *testing.T) {
+	// given
+	config, execPath := writeDoctorSetup(t.TempDir(), t)
+	config.DocsPath = filepath.Join(filepath.Dir(execPath), "Missing.pdf")
+	os.WriteFile(GetFullPathForFileInExecDir(execPath, model.PluginConfigurationIniFileName), []byte("[FilterPlugin]\n"), 0644)
+	os.WriteFile(config.PluginPath, []byte("not json"), 0644)
+
+	// when
+	results := RunDoctor(config, []string{"5.4", "5.5"}, execPath)
+
+	// then
+	expected := map[string]CheckStatus{
+		"UE 5.4":                             CheckPass,
+		"UE 5.5":                             CheckFail,
+		"pluginPath":                         CheckPass,
+		"plugin descriptor":                  CheckFail,
+		"docsPath":                           CheckFail,
+		model.PluginConfigurationIniFileName: CheckFail,
+		"outputBaseDirectory":                CheckPass,
+		"output write permission":            CheckPass,
+	}
+	for item, status := range expected {
+		if actual := statusOf(results, item); actual != status {
+			t.Errorf("%s: expected %q, got %q", item, status, actual)
+		}
+	}
+}
+
+func TestDoctorShouldFailAnEngineOfAnotherVersion(t *testing.T) {
+	config, execPath := writeDoctorSetup(t.TempDir(), t)
+	writeBuildVersion(filepath.Join(config.EngineBaseDirectory, "UE_5.4"), `{"MajorVersion": 5, "MinorVersion": 3, "PatchVersion": 2}`, t)
+
+	results := RunDoctor(config, []string{"5.4"}, execPath)
+
+	if status := statusOf(results, "UE 5.4"); status != CheckFail {
+		t.Errorf("An engine of another version should fail, got %q", status)
+	}
+}
+
+// an engine, a plugin, docs with their ini and an output folder that all work together
+func writeDoctorSetup(base string, t *testing.T) (*model.Config, string) {
+	t.Helper()
+	config := writeTestProject(base, []string{"5.4"}, t)
+	writeBuildVersion(filepath.Join(config.EngineBaseDirectory, "UE_5.4"), `{"MajorVersion": 5, "MinorVersion": 4, "PatchVersion": 1}`, t)
+	writeFilterPluginFile(base, t)
+	config.DocsPath = makeFile(base, "My_Docs.pdf", t)
+	return config, filepath.Join(base, "script.exe")
+}
+
+func statusOf(results []CheckResult, item string) CheckStatus {
+	for _, result := range results {
+		if result.Item == item {
+			return result.Status
+		}
+	}
+	return ""
+}
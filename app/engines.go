@@ -0,0 +1,192 @@
+// finds the engine installations and their build scripts for the requested versions.
+package app
+
+import (
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+const (
+	engineSourceConfig        = "engines"
+	engineSourceBaseDirectory = "engineBaseDirectory"
+)
+
+/*
+Finds installed engines in a single place, like the config or the Epic Launcher's records.
+*/
+type EngineLocator interface {
+	LocateEngines() ([]model.EngineInstall, error)
+}
+
+/*
+Resolves engine versions to installations, merging what its locators found.
+Later locators take priority over earlier ones for the same version.
+*/
+type EngineResolver struct {
+	config   *model.Config
+	locators []EngineLocator
+	log      *slog.Logger
+}
+
+/*
+Constructor for the engine resolver with the default locators: the Epic Launcher's records,
+then the engine base directory, then the engines mappings of the config, which take priority over everything else.
+*/
+func NewEngineResolver(config *model.Config) *EngineResolver {
+	return NewEngineResolverWithFileSystem(config, OSFileSystem{})
+}
+
+/*
+Constructor for the engine resolver with the default locators, looking for the engines through the file system.
+*/
+func NewEngineResolverWithFileSystem(config *model.Config, fsys FileSystem) *EngineResolver {
+	return NewEngineResolverWithLocators(config,
+		LauncherEngineLocator{
+			Path:            launcherInstalledPathFor(config),
+			BuildScriptPath: config.BuildScriptPath,
+			Explicit:        config.LauncherInstalledPath != "",
+			FileSystem:      fsys,
+		},
+		baseDirectoryEngineLocator{config, fsys},
+		mappedEngineLocator{config, fsys},
+	)
+}
+
+/*
+Constructor for the engine resolver with custom locators, in order of increasing priority.
+*/
+func NewEngineResolverWithLocators(config *model.Config, locators ...EngineLocator) *EngineResolver {
+	return &EngineResolver{config: config, locators: locators, log: defaultLogger()}
+}
+
+/*
+Lists every engine the locators know about, sorted by version.
+*/
+func (r *EngineResolver) FindEngines() []model.EngineInstall {
+	return sortEngineInstalls(r.locateAll())
+}
+
+/*
+Resolves the engine for the version, falling back to the engine base directory convention if no locator knows it.
+*/
+func (r *EngineResolver) Resolve(version string) model.EngineInstall {
+	if install, ok := r.locateAll()[version]; ok {
+		return install
+	}
+	return resolveConventionalEngine(r.config, version)
+}
+
+func (r *EngineResolver) locateAll() map[string]model.EngineInstall {
+	found := map[string]model.EngineInstall{}
+	for _, locator := range r.locators {
+		installs, err := locator.LocateEngines()
+		if err != nil {
+			r.log.Warn("failed to locate engines", "error", err)
+			continue
+		}
+		for _, install := range installs {
+			found[install.Version] = install
+		}
+	}
+	return found
+}
+
+// the engines mapped explicitly in the config
+type mappedEngineLocator struct {
+	config *model.Config
+	fs     FileSystem
+}
+
+func (l mappedEngineLocator) LocateEngines() ([]model.EngineInstall, error) {
+	var installs []model.EngineInstall
+	for version := range l.config.Engines {
+		installs = append(installs, resolveMappedEngine(l.fs, l.config, version))
+	}
+	return installs, nil
+}
+
+// the UE_<version> folders of the engine base directory
+type baseDirectoryEngineLocator struct {
+	config *model.Config
+	fs     FileSystem
+}
+
+func (l baseDirectoryEngineLocator) LocateEngines() ([]model.EngineInstall, error) {
+	if l.config.EngineBaseDirectory == "" {
+		return nil, nil
+	}
+
+	entries, err := l.fs.ReadDir(l.config.EngineBaseDirectory)
+	if err != nil {
+		return nil, nil
+	}
+
+	var installs []model.EngineInstall
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "UE_") {
+			installs = append(installs, resolveConventionalEngine(l.config, strings.TrimPrefix(entry.Name(), "UE_")))
+		}
+	}
+	return installs, nil
+}
+
+func resolveConventionalEngine(config *model.Config, version string) model.EngineInstall {
+	return model.EngineInstall{
+		Version:         version,
+		Root:            filepath.Join(config.EngineBaseDirectory, "UE_"+version),
+		BuildScriptPath: createBatFilePath(config.EngineBaseDirectory, version, config.BuildScriptPath),
+		Source:          engineSourceBaseDirectory,
+	}
+}
+
+// a mapping is either the engine root, or the full path of its build script
+func resolveMappedEngine(fsys FileSystem, config *model.Config, version string) model.EngineInstall {
+	mapped := config.Engines[version]
+	install := model.EngineInstall{Version: version, Source: engineSourceConfig}
+
+	if isFileIn(fsys, mapped) {
+		install.BuildScriptPath = mapped
+		install.Root = findEngineRoot(mapped)
+	} else {
+		install.Root = mapped
+		install.BuildScriptPath = filepath.Join(mapped, config.BuildScriptPath)
+	}
+	return install
+}
+
+// the folder that contains the Engine folder the script is in, or the script's folder if it's not in one
+func findEngineRoot(scriptPath string) string {
+	current := filepath.Dir(scriptPath)
+	for {
+		if strings.EqualFold(filepath.Base(current), "Engine") {
+			return filepath.Dir(current)
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return filepath.Dir(scriptPath)
+		}
+		current = parent
+	}
+}
+
+func sortEngineInstalls(found map[string]model.EngineInstall) []model.EngineInstall {
+	installs := make([]model.EngineInstall, 0, len(found))
+	for _, install := range found {
+		installs = append(installs, install)
+	}
+
+	sort.Slice(installs, func(i, j int) bool {
+		a, errA := parseEngineVersion(installs[i].Version)
+		b, errB := parseEngineVersion(installs[j].Version)
+		if errA != nil || errB != nil {
+			return installs[i].Version < installs[j].Version
+		}
+		return compareEngineVersions(a, b) < 0
+	})
+	return installs
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestResolveEngineShouldPreferMappedEngineRoot(t *testing.T) {
+	// given
+	customRoot := makeDir(t.TempDir(), "UE5-Custom", t)
+	config := model.Config{
+		EngineBaseDirectory: t.TempDir(),
+		BuildScriptPath:     filepath.Join("Engine", "Build", "BatchFiles", "RunUAT.bat"),
+		Engines:             map[string]string{"5.4": customRoot},
+	}
+
+	// when
+	actual := NewEngineResolverWithLocators(&config, mappedEngineLocator{&config, OSFileSystem{}}).Resolve("5.4")
+
+	// then
+	expected := filepath.Join(customRoot, config.BuildScriptPath)
+	if actual.BuildScriptPath != expected || actual.Root != customRoot || actual.Source != engineSourceConfig {
+		t.Errorf("Expected the mapped root with script %q, got %+v", expected, actual)
+	}
+}
+
+func TestResolveEngineShouldAcceptFullScriptPath(t *testing.T) {
+	// given
+	root := filepath.Join(t.TempDir(), "UE5-Custom")
+	script := makeFile(filepath.Join(root, "Engine", "Build", "BatchFiles"), "RunUAT.bat", t)
+	config := model.Config{
+		BuildScriptPath: "ignored",
+		Engines:         map[string]string{"5.5": script},
+	}
+
+	// when
+	actual := NewEngineResolverWithLocators(&config, mappedEngineLocator{&config, OSFileSystem{}}).Resolve("5.5")
+
+	// then
+	if actual.BuildScriptPath != script || actual.Root != root {
+		t.Errorf("Expected script %q in root %q, got %+v", script, root, actual)
+	}
+}
+
+func TestResolveEngineShouldFallBackToBaseDirectory(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := model.Config{EngineBaseDirectory: base, BuildScriptPath: "RunUAT.bat"}
+
+	// when
+	actual := NewEngineResolverWithLocators(&config, baseDirectoryEngineLocator{&config, OSFileSystem{}}).Resolve("5.3")
+
+	// then
+	if actual.BuildScriptPath != filepath.Join(base, "UE_5.3", "RunUAT.bat") || actual.Source != engineSourceBaseDirectory {
+		t.Errorf("Expected the engine base directory convention, got %+v", actual)
+	}
+}
+
+func TestFindEnginesShouldMergeMappingsOverBaseDirectory(t *testing.T) {
+	// given
+	base := t.TempDir()
+	writeBuildScript(base, "5.3", "RunUAT.bat", t)
+	writeBuildScript(base, "5.4", "RunUAT.bat", t)
+	customRoot := makeDir(t.TempDir(), "UE5-Custom", t)
+	config := model.Config{
+		EngineBaseDirectory: base,
+		BuildScriptPath:     "RunUAT.bat",
+		Engines:             map[string]string{"5.4": customRoot, "5.10": customRoot},
+	}
+
+	// when
+	actual := NewEngineResolverWithLocators(&config, baseDirectoryEngineLocator{&config, OSFileSystem{}}, mappedEngineLocator{&config, OSFileSystem{}}).FindEngines()
+
+	// then
+	if len(actual) != 3 {
+		t.Fatalf("Expected 3 engines, got %+v", actual)
+	}
+	if actual[0].Version != "5.3" || actual[1].Version != "5.4" || actual[2].Version != "5.10" {
+		t.Errorf("Engines should be sorted by version, got %+v", actual)
+	}
+	if actual[1].Root != customRoot {
+		t.Errorf("The mapping should win over the base directory, got %+v", actual[1])
+	}
+}
+
+func TestResolverShouldPreferLaterLocators(t *testing.T) {
+	// given
+	config := model.Config{}
+	launcher := fixedEngineLocator{installs: []model.EngineInstall{{Version: "5.3", Root: "launcher"}, {Version: "5.2", Root: "launcher"}}}
+	baseDirectory := fixedEngineLocator{installs: []model.EngineInstall{{Version: "5.3", Root: "base"}}}
+	broken := fixedEngineLocator{err: errors.New("unreadable")}
+	underTest := NewEngineResolverWithLocators(&config, launcher, broken, baseDirectory)
+
+	// when
+	actual := underTest.FindEngines()
+
+	// then
+	if len(actual) != 2 || actual[0].Root != "launcher" || actual[1].Root != "base" {
+		t.Errorf("Expected 5.2 from the launcher and 5.3 from the base directory, got %+v", actual)
+	}
+}
+
+// locator returning the same engines every time, for tests
+type fixedEngineLocator struct {
+	installs []model.EngineInstall
+	err      error
+}
+
+func (l fixedEngineLocator) LocateEngines() ([]model.EngineInstall, error) {
+	return l.installs, l.err
+}
+
+// the OS file system, remembering the paths it was asked to read
+type recordingFileSystem struct {
+	OSFileSystem
+	read []string
+}
+
+func (r *recordingFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	r.read = append(r.read, name)
+	return r.OSFileSystem.ReadDir(name)
+}
+
+func (r *recordingFileSystem) ReadFile(name string) ([]byte, error) {
+	r.read = append(r.read, name)
+	return r.OSFileSystem.ReadFile(name)
+}
+
+func TestEnginesShouldBeFoundThroughTheFileSystemOfTheBuilder(t *testing.T) {
+	// given
+	base := t.TempDir()
+	writeBuildVersion(filepath.Join(base, "UE_5.3"), testBuildVersion, t)
+	launcher := filepath.Join(t.TempDir(), "LauncherInstalled.dat")
+	config := model.Config{EngineBaseDirectory: base, BuildScriptPath: "RunUAT.bat", LauncherInstalledPath: launcher}
+	fsys := &recordingFileSystem{}
+	underTest := NewPluginBuilderWithOptions(&config, FakeExecutor{}, BuilderOptions{FileSystem: fsys})
+
+	// when
+	engines := underTest.engines.FindEngines()
+	_, err := underTest.verifyEngineIdentity("5.3")
+
+	// then
+	if len(engines) != 1 || err != nil {
+		t.Fatalf("The engine should be found and verified, got %+v (%v)", engines, err)
+	}
+	for _, path := range []string{launcher, base, filepath.Join(base, "UE_5.3", "Engine", "Build", "Build.version")} {
+		if !slices.Contains(fsys.read, path) {
+			t.Errorf("%s should be read through the file system, read %v", path, fsys.read)
+		}
+	}
+}
@@ -3,10 +3,13 @@ package app
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"unreal-plugin-release/model"
@@ -14,16 +17,11 @@ import (
 
 /*
 Create the configuration dto from the config file, by location.
+Unknown fields are rejected, so a typo doesn't silently leave a setting out.
 */
 func CreateConfig(path string) (*model.Config, error) {
-	file := openConfigFile(path)
-	decoder := json.NewDecoder(file)
-	defer file.Close()
-	config := model.Config{}
-	if err := decoder.Decode(&config); err != nil {
-		return nil, err
-	}
-	return &config, nil
+	config, _, err := LoadConfig(path, ConfigLayers{})
+	return config, err
 }
 
 /*
@@ -66,118 +64,173 @@ func IsFile(path string) bool {
 	return !info.IsDir()
 }
 
-func openConfigFile(path string) *os.File {
-	f, err := os.Open(path)
-	if err != nil {
-		fmt.Println("Error opening config file:", err)
-		os.Exit(1)
-	}
-	return f
-}
-
 func createPluginName(pluginLocation string) string {
 	return strings.TrimSuffix(filepath.Base(pluginLocation), filepath.Ext(pluginLocation))
 }
 
-func combineOutputDir(pluginName string, version string, outputDir string) string {
-	return filepath.Join(outputDir, pluginName+"_"+version)
-}
-
 func createBatFilePath(engineBaseDir string, version string, buildScriptPath string) string {
 	return filepath.Join(engineBaseDir, "UE_"+version, buildScriptPath)
 }
 
-func isFilePathValid(path string) bool {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+func collectPlatforms(input string) []string {
+	var platforms []string
+	for _, platform := range strings.Split(input, ",") {
+		if platform = strings.TrimSpace(platform); platform != "" {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
+
+// the UAT arguments that restrict the build to the given platforms, none if the platforms are left to UAT
+func createPlatformArguments(platforms []string) []string {
+	if len(platforms) == 0 {
+		return nil
+	}
+	return []string{"-TargetPlatforms=" + strings.Join(platforms, "+"), "-NoHostPlatform"}
+}
+
+func isFilePathValid(fsys FileSystem, path string) bool {
+	if _, err := fsys.Stat(path); errors.Is(err, fs.ErrNotExist) {
 		return false
 	}
 
 	return true
 }
 
-func removeDirectory(path string) {
-	if !isDangerousPath(path) {
-		if removeErr := os.RemoveAll(path); removeErr != nil {
-			fmt.Println("Failed to remove output directory", removeErr)
+func removeDirectory(guard *DeletionGuard, path string) {
+	guard.Remove(path)
+}
+
+func deleteSubFolder(guard *DeletionGuard, baseDir string, dirToDelete string) {
+	guard.Remove(filepath.Join(baseDir, dirToDelete))
+}
+
+// the files published next to a release folder, named after it
+var releaseFileSuffixes = []string{".zip", ".zip" + checksumFileExtension}
+
+/*
+Moves a fully processed release, its archive and checksum from the staging area into the output directory.
+A previous release with the same name is only removed once the new one is in place.
+*/
+func publishRelease(guard *DeletionGuard, stagingDir string, outputDir string) error {
+	if err := replaceWithRename(guard, stagingDir, outputDir); err != nil {
+		return fmt.Errorf("failed to publish release: %w", err)
+	}
+	if err := markOwned(guard.fs, filepath.Dir(outputDir), filepath.Base(outputDir)); err != nil {
+		return fmt.Errorf("failed to mark release as published: %w", err)
+	}
+
+	for _, suffix := range releaseFileSuffixes {
+		stagedFile := stagingDir + suffix
+		if !pathExists(guard.fs, stagedFile) {
+			continue
+		}
+		if err := replaceWithRename(guard, stagedFile, outputDir+suffix); err != nil {
+			return fmt.Errorf("failed to publish %s: %w", filepath.Base(outputDir+suffix), err)
+		}
+		if err := markOwned(guard.fs, filepath.Dir(outputDir), filepath.Base(outputDir)+suffix); err != nil {
+			return fmt.Errorf("failed to mark %s as published: %w", filepath.Base(outputDir+suffix), err)
 		}
-	} else {
-		fmt.Println("Output directory is dangerous to delete.")
 	}
+
+	return nil
 }
 
-func isDangerousPath(path string) bool {
-	lower := strings.ToLower(filepath.Clean(path))
+func replaceWithRename(guard *DeletionGuard, source string, target string) error {
+	previous := source + ".previous"
+	if pathExists(guard.fs, target) {
+		if err := guard.fs.Rename(target, previous); err != nil {
+			return err
+		}
+	}
 
-	// Check for root drive (C:\, D:\, etc.)
-	vol := filepath.VolumeName(lower)
-	root := filepath.Join(vol, "\\")
+	if err := guard.fs.Rename(source, target); err != nil {
+		if pathExists(guard.fs, previous) {
+			guard.fs.Rename(previous, target)
+		}
+		return err
+	}
 
-	if lower == root {
-		return true
+	if pathExists(guard.fs, previous) {
+		removeDirectory(guard, previous)
 	}
+	return nil
+}
 
-	// Check if it contains "windows" directory
-	if strings.Contains(lower, `\windows`) {
-		return true
+func discardStagedRelease(guard *DeletionGuard, stagingDir string) {
+	removeDirectory(guard, stagingDir)
+	for _, suffix := range releaseFileSuffixes {
+		if pathExists(guard.fs, stagingDir+suffix) {
+			removeDirectory(guard, stagingDir+suffix)
+		}
 	}
+}
 
-	return false
+/*
+Creates a directory that is marked as created by the tool, so it can be safely deleted later.
+*/
+func createOwnedDirectory(fsys FileSystem, path string) error {
+	if err := fsys.MkdirAll(path, os.ModePerm); err != nil {
+		return err
+	}
+	return markOwned(fsys, path, ownedDirectoryEntry)
 }
 
-func deleteSubFolder(baseDir string, dirToDelete string) {
-	fullPath := filepath.Join(baseDir, dirToDelete)
-	if err := os.RemoveAll(fullPath); err != nil {
-		fmt.Println("⚠️ Failed to delete:", fullPath, "->", err)
+// removes the directory only if nothing but the ownership marker is left inside
+func removeEmptyDirectory(guard *DeletionGuard, path string) {
+	entries, err := guard.fs.ReadDir(path)
+	if err != nil {
+		return
 	}
+
+	for _, entry := range entries {
+		if entry.Name() != model.OwnershipMarkerFile {
+			return
+		}
+	}
+	guard.Remove(path)
 }
 
-func createConfigFolderWithIni(releaseDir string, sourceIniPath string) error {
+func createConfigFolderWithIni(fsys FileSystem, releaseDir string, sourceIniPath string) error {
 	configDir := filepath.Join(releaseDir, model.ConfigDirectoryName)
-	if err := os.MkdirAll(configDir, os.ModePerm); err != nil {
-		fmt.Println("⚠️ Failed to create Config dir:", err)
-		return err
+	if err := fsys.MkdirAll(configDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create Config dir: %w", err)
 	}
 
 	destIni := filepath.Join(configDir, model.PluginConfigurationIniFileName)
-	if err := copyFile(sourceIniPath, destIni); err != nil {
-		fmt.Println("⚠️ Failed to copy INI file:", err)
-		return err
+	if err := copyFile(fsys, sourceIniPath, destIni); err != nil {
+		return fmt.Errorf("failed to copy INI file: %w", err)
 	}
 
 	return nil
 }
 
-func copyPdfIntoDocsFolderAndRename(releaseDir string, docsPath string, filterPluginFilePath string) error {
+func copyPdfIntoDocsFolderAndRename(fsys FileSystem, releaseDir string, docsPath string, filterPluginFilePath string) error {
 	// 1. Read FilterPlugin.ini next to the .exe
-	filterData, err := os.ReadFile(filterPluginFilePath)
+	docsPathInPlugin, err := readDocsPathInPlugin(fsys, filterPluginFilePath)
 	if err != nil {
-		return fmt.Errorf("failed to read FilterPlugin.ini: %w", err)
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(filterData)), "\n")
-	if len(lines) < 2 {
-		return fmt.Errorf("FilterPlugin.ini must contain a second line for the doc path")
+		return err
 	}
 
 	// 2. Get the relative path from the second line (e.g. /Docs/DocName.pdf)
-	relativeDocPath := strings.TrimSpace(lines[1])
-	relativeDocPath = strings.TrimPrefix(relativeDocPath, "/")
+	relativeDocPath := strings.TrimPrefix(docsPathInPlugin, "/")
 	targetDocPath := filepath.Join(releaseDir, relativeDocPath)
 
 	// 3. Ensure target directories exist
 	targetDir := filepath.Dir(targetDocPath)
-	if err := os.MkdirAll(targetDir, os.ModePerm); err != nil {
+	if err := fsys.MkdirAll(targetDir, os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create doc target folder: %w", err)
 	}
 
 	// 4. Copy the documentation PDF to the correct path
-	srcFile, err := os.Open(docsPath)
+	srcFile, err := fsys.Open(docsPath)
 	if err != nil {
 		return fmt.Errorf("failed to open documentation file: %w", err)
 	}
 	defer srcFile.Close()
 
-	destFile, err := os.Create(targetDocPath)
+	destFile, err := fsys.Create(targetDocPath)
 	if err != nil {
 		return fmt.Errorf("failed to create destination doc file: %w", err)
 	}
@@ -190,14 +243,60 @@ func copyPdfIntoDocsFolderAndRename(releaseDir string, docsPath string, filterPl
 	return nil
 }
 
-func copyFile(src, dest string) error {
-	input, err := os.Open(src)
+// the second line of FilterPlugin.ini is where the docs go inside the plugin, e.g. /Docs/DocName.pdf
+func readDocsPathInPlugin(fsys FileSystem, filterPluginFilePath string) (string, error) {
+	filterData, err := fsys.ReadFile(filterPluginFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read FilterPlugin.ini: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(filterData)), "\n")
+	if len(lines) < 2 {
+		return "", fmt.Errorf("FilterPlugin.ini must contain a second line for the doc path")
+	}
+	return strings.TrimSpace(lines[1]), nil
+}
+
+/*
+Copies the directory recursively, leaving out the excluded top level entries and hidden files like .git.
+A folder holding the destination is left out too, so an output directory inside the plugin is not copied into itself.
+*/
+func copyDirectory(fsys FileSystem, src string, dest string, excluded []string) error {
+	if err := fsys.MkdirAll(dest, os.ModePerm); err != nil {
+		return err
+	}
+
+	normalizedDest := normalizePath(dest)
+	return walkDirectory(fsys, src, func(path string, entry fs.DirEntry) error {
+		relative, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(entry.Name(), ".") || (filepath.Dir(relative) == "." && slices.Contains(excluded, entry.Name())) ||
+			isWithinPath(normalizedDest, normalizePath(path)) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(dest, relative)
+		if entry.IsDir() {
+			return fsys.MkdirAll(target, os.ModePerm)
+		}
+		return copyFile(fsys, path, target)
+	})
+}
+
+func copyFile(fsys FileSystem, src, dest string) error {
+	input, err := fsys.Open(src)
 	if err != nil {
 		return err
 	}
 	defer input.Close()
 
-	output, err := os.Create(dest)
+	output, err := fsys.Create(dest)
 	if err != nil {
 		return err
 	}
@@ -206,3 +305,28 @@ func copyFile(src, dest string) error {
 	_, err = io.Copy(output, input)
 	return err
 }
+
+/*
+Reads how long every version took the last time it was built, in seconds, kept across batches.
+*/
+func ReadBuildDurations(path string) (map[string]float64, error) {
+	return readBuildDurations(OSFileSystem{}, path)
+}
+
+func readBuildDurations(fsys FileSystem, path string) (map[string]float64, error) {
+	durations := map[string]float64{}
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return durations, err
+	}
+	err = json.Unmarshal(data, &durations)
+	return durations, err
+}
+
+func writeBuildReport(fsys FileSystem, path string, report model.BuildReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsys.WriteFile(path, data, 0644)
+}
@@ -2,19 +2,12 @@ package app
 
 import (
 	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 	"testing"
 	"unreal-plugin-release/model"
 )
 
-type isDangerousPathTestData struct {
-	path     string
-	expected bool
-}
-
 func TestCreateValidConfigShouldReturnConfigWithNoError(t *testing.T) {
 	// given
 	tempDir := t.TempDir()
@@ -182,21 +175,6 @@ func TestCreatePluginNameShouldReturnThePluginNameWithoutExtension(t *testing.T)
 	}
 }
 
-func TestCombineOutputDirShouldPutPluginNameAndVersionTogether(t *testing.T) {
-	// given
-	tempDir := t.TempDir()
-	expected := tempDir + "\\MyPlugin_5.5"
-	fmt.Printf("Expected directory: %q", expected)
-
-	// when
-	actual := combineOutputDir("MyPlugin", "5.5", tempDir)
-
-	// then
-	if expected != actual {
-		t.Errorf("Expected: %q, Actual: %q", expected, actual)
-	}
-}
-
 func TestCreateBatFilePathShouldCombinePathWithEngineBaseDir(t *testing.T) {
 	// given
 	engineBase := t.TempDir()
@@ -223,7 +201,7 @@ func TestExistingFilePathShouldBeValid(t *testing.T) {
 	f.Close()
 
 	// when
-	actual := isFilePathValid(file)
+	actual := isFilePathValid(OSFileSystem{}, file)
 
 	// then
 	if !actual {
@@ -237,7 +215,7 @@ func TestNonExistingFilePathShouldBeInvalid(t *testing.T) {
 	file := filepath.Join(tempDir, "file.txt")
 
 	// when
-	actual := isFilePathValid(file)
+	actual := isFilePathValid(OSFileSystem{}, file)
 
 	// then
 	if actual {
@@ -245,16 +223,16 @@ func TestNonExistingFilePathShouldBeInvalid(t *testing.T) {
 	}
 }
 
-func TestRemoveDirectoryShouldRemoveNonDangerousPath(t *testing.T) {
+func TestRemoveDirectoryShouldRemoveOwnedPath(t *testing.T) {
 	// given
 	tempDir := t.TempDir()
 	path := filepath.Join(tempDir, "Directory")
-	if err := os.MkdirAll(path, 0755); err != nil {
+	if err := createOwnedDirectory(OSFileSystem{}, path); err != nil {
 		t.Fatal("Failed to create dir")
 	}
 
 	// when
-	removeDirectory(path)
+	removeDirectory(newUnprotectedGuard(), path)
 
 	// then
 	if _, err := os.Stat(path); err == nil {
@@ -262,16 +240,16 @@ func TestRemoveDirectoryShouldRemoveNonDangerousPath(t *testing.T) {
 	}
 }
 
-func TestRemoveDirectoryShouldNotRemoveDangerousPath(t *testing.T) {
+func TestRemoveDirectoryShouldNotRemoveForeignPath(t *testing.T) {
 	// given
 	tempDir := t.TempDir()
-	path := filepath.Join(tempDir, "windows\\directory")
+	path := filepath.Join(tempDir, "directory")
 	if err := os.MkdirAll(path, 0755); err != nil {
 		t.Fatal("Failed to create dir")
 	}
 
 	// when
-	removeDirectory(path)
+	removeDirectory(newUnprotectedGuard(), path)
 
 	// then
 	if _, err := os.Stat(path); err != nil {
@@ -279,20 +257,6 @@ func TestRemoveDirectoryShouldNotRemoveDangerousPath(t *testing.T) {
 	}
 }
 
-func TestIsDangerousPath(t *testing.T) {
-	for i, tt := range createIsDangerousPathTestData() {
-		t.Run("EngineVersionTest #"+strconv.Itoa(i), func(t *testing.T) {
-			// when
-			actual := isDangerousPath(tt.path)
-
-			// then
-			if tt.expected != actual {
-				t.Errorf("%d case: expected: %v, actual: %v", i, tt.expected, actual)
-			}
-		})
-	}
-}
-
 func TestDeleteSubFolder(t *testing.T) {
 	// given
 	base := t.TempDir()
@@ -301,9 +265,10 @@ func TestDeleteSubFolder(t *testing.T) {
 	if err := os.Mkdir(path, 0755); err != nil {
 		t.Fatal("Failed to create dir")
 	}
+	markOwned(OSFileSystem{}, base, ownedDirectoryEntry)
 
 	// when
-	deleteSubFolder(base, subfolderName)
+	deleteSubFolder(newUnprotectedGuard(), base, subfolderName)
 
 	// then
 	_, subfolderErr := os.Stat(path)
@@ -326,7 +291,7 @@ func TestCreateConfigFolderWithIni(t *testing.T) {
 	os.WriteFile(filePath, content, 0755)
 
 	// when
-	createConfigFolderWithIni(releaseDir, filePath)
+	createConfigFolderWithIni(OSFileSystem{}, releaseDir, filePath)
 
 	// then
 	copiedData, err := os.ReadFile(filepath.Join(releaseDir, "Config", "FilterPlugin.ini"))
@@ -347,7 +312,7 @@ func TestCopyPdfIntoDocsFolderAndRename(t *testing.T) {
 	filterPluginPath := filepath.Join(testDataFolder, "FilterPluginTest.ini")
 
 	// when
-	copyPdfIntoDocsFolderAndRename(releaseDir, docsPath, filterPluginPath)
+	copyPdfIntoDocsFolderAndRename(OSFileSystem{}, releaseDir, docsPath, filterPluginPath)
 
 	// then
 	data, err := os.ReadFile(filepath.Join(releaseDir, "Docs", "My_Docs.pdf"))
@@ -360,36 +325,100 @@ func TestCopyPdfIntoDocsFolderAndRename(t *testing.T) {
 	}
 }
 
-// test data
-func createIsDangerousPathTestData() []isDangerousPathTestData {
-	return []isDangerousPathTestData{
-		{
-			"C:\\",
-			true,
-		},
-		{
-			"D:\\",
-			true,
-		},
-		{
-			"E:\\",
-			true,
-		},
-		{
-			"C:\\Windows\\AnyDirectory",
-			true,
-		},
-		{
-			"D:\\Windows\\AnyDirectory",
-			true,
-		},
-		{
-			"D:\\Games\\MyPlugin\\Release",
-			false,
-		},
-		{
-			"C:\\Users\\JohnDoe\\Documents\\Plugins\\MyPlugin",
-			false,
-		},
+func TestPublishReleaseShouldReplacePreviousRelease(t *testing.T) {
+	// given
+	base := t.TempDir()
+	stagingDir := filepath.Join(base, ".staging", "MyPlugin_5.4")
+	outputDir := filepath.Join(base, "MyPlugin_5.4")
+	createOwnedDirectory(OSFileSystem{}, filepath.Join(base, ".staging"))
+	makeFile(stagingDir, "New.txt", t)
+	makeFile(filepath.Join(base, ".staging"), "MyPlugin_5.4.zip", t)
+	makeFile(outputDir, "Old.txt", t)
+	guard := newUnprotectedGuard()
+	guard.protectPath(base)
+
+	// when
+	err := publishRelease(guard, stagingDir, outputDir)
+
+	// then
+	if err != nil {
+		t.Fatalf("Publishing should not fail: %v", err)
+	}
+	if !isFileExist(filepath.Join(outputDir, "New.txt")) || isFileExist(filepath.Join(outputDir, "Old.txt")) {
+		t.Error("The published release should replace the previous one.")
+	}
+	if !isFileExist(outputDir + ".zip") {
+		t.Error("The archive should have been published next to the release.")
+	}
+	if IsPathExist(stagingDir) || IsPathExist(stagingDir+".previous") {
+		t.Error("Nothing should be left in the staging area.")
+	}
+	if !isOwnedByTool(OSFileSystem{}, outputDir) || !isOwnedByTool(OSFileSystem{}, outputDir+".zip") {
+		t.Error("The published release should be marked as created by the tool.")
+	}
+}
+
+func TestDiscardStagedReleaseShouldOnlyRemoveStagingArea(t *testing.T) {
+	// given
+	base := t.TempDir()
+	stagingDir := filepath.Join(base, ".staging", "MyPlugin_5.4")
+	published := makeFile(filepath.Join(base, "MyPlugin_5.3"), "MyPlugin.uplugin", t)
+	createOwnedDirectory(OSFileSystem{}, filepath.Join(base, ".staging"))
+	makeFile(stagingDir, "MyPlugin.uplugin", t)
+
+	// when
+	discardStagedRelease(newUnprotectedGuard(), stagingDir)
+
+	// then
+	if IsPathExist(stagingDir) {
+		t.Error("The staging directory should have been removed.")
+	}
+	if !isFileExist(published) {
+		t.Error("Already published releases must be left intact.")
+	}
+}
+
+func TestCopyDirectoryShouldLeaveOutExcludedAndHiddenEntries(t *testing.T) {
+	// given
+	base := t.TempDir()
+	makeFile(filepath.Join(base, "Source"), "MyActor.cpp", t)
+	makeFile(filepath.Join(base, "Binaries"), "MyPlugin.dll", t)
+	makeFile(filepath.Join(base, ".git"), "HEAD", t)
+	makeFile(filepath.Join(base, "Content", "Binaries"), "Asset.uasset", t)
+	dest := filepath.Join(base, "Output", ".staging", "MyPlugin_5.4")
+
+	// when
+	err := copyDirectory(OSFileSystem{}, base, dest, []string{"Binaries"})
+
+	// then
+	if err != nil {
+		t.Fatalf("Copying should not fail: %v", err)
+	}
+	if !isFileExist(filepath.Join(dest, "Source", "MyActor.cpp")) {
+		t.Error("Source should have been copied.")
+	}
+	if !isFileExist(filepath.Join(dest, "Content", "Binaries", "Asset.uasset")) {
+		t.Error("Only top level entries should be excluded.")
+	}
+	if IsPathExist(filepath.Join(dest, "Binaries")) || IsPathExist(filepath.Join(dest, ".git")) {
+		t.Error("Excluded and hidden entries should have been left out.")
+	}
+	if IsPathExist(filepath.Join(dest, "Output")) {
+		t.Error("The destination must not be copied into itself.")
+	}
+}
+
+func TestCreatePlatformArguments(t *testing.T) {
+	// when
+	actual := createPlatformArguments(collectPlatforms("Win64, Linux,Android"))
+	none := createPlatformArguments(collectPlatforms(""))
+
+	// then
+	expected := []string{"-TargetPlatforms=Win64+Linux+Android", "-NoHostPlatform"}
+	if !arrayContainsAll(expected, actual) {
+		t.Errorf("Expected: %v, Actual: %v", expected, actual)
+	}
+	if len(none) != 0 {
+		t.Errorf("No platforms should not restrict the build, got %v", none)
 	}
 }
@@ -0,0 +1,143 @@
+// the file operations of a release, behind an interface so programs embedding the tool can redirect or observe them.
+package app
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+/*
+The file operations the release does on the plugin, the staging area and the output directory.
+The executor's commands, like UAT or the zip, still work on the disk, so a FileSystem other than OSFileSystem
+needs an executor whose commands go through it too.
+*/
+type FileSystem interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	Open(name string) (io.ReadCloser, error)
+	Create(name string) (io.WriteCloser, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	MkdirTemp(dir string, pattern string) (string, error)
+	Rename(oldPath string, newPath string) error
+	Remove(name string) error
+	RemoveAll(path string) error
+}
+
+// the file system of the OS, used unless another one is given
+type OSFileSystem struct {
+}
+
+func (OSFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (OSFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (OSFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (OSFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+func (OSFileSystem) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (OSFileSystem) Create(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+
+func (OSFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (OSFileSystem) MkdirTemp(dir string, pattern string) (string, error) {
+	return os.MkdirTemp(dir, pattern)
+}
+
+func (OSFileSystem) Rename(oldPath string, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+func (OSFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (OSFileSystem) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+// a file system that can create a file only if it doesn't exist yet, in a single step
+type ExclusiveCreator interface {
+	CreateExclusive(name string) (io.WriteCloser, error)
+}
+
+func (OSFileSystem) CreateExclusive(name string) (io.WriteCloser, error) {
+	return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+}
+
+/*
+Writes a new file, failing with fs.ErrExist if it's already there.
+It's only a single step on a file system implementing ExclusiveCreator, others are checked first and written after.
+*/
+func createExclusive(fsys FileSystem, name string, data []byte) error {
+	creator, ok := fsys.(ExclusiveCreator)
+	if !ok {
+		if pathExists(fsys, name) {
+			return &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+		}
+		return fsys.WriteFile(name, data, 0644)
+	}
+
+	file, err := creator.CreateExclusive(name)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func pathExists(fsys FileSystem, path string) bool {
+	_, err := fsys.Stat(path)
+	return err == nil
+}
+
+func isFileIn(fsys FileSystem, path string) bool {
+	info, err := fsys.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// walks the directory tree like filepath.WalkDir, through the file system
+func walkDirectory(fsys FileSystem, root string, visit func(path string, entry fs.DirEntry) error) error {
+	entries, err := fsys.ReadDir(root)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(root, entry.Name())
+		if err := visit(path, entry); err != nil {
+			if err == filepath.SkipDir {
+				continue
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if err := walkDirectory(fsys, path, visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
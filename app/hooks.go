@@ -0,0 +1,123 @@
+// runs the user's commands from the hooks of the config at points of the batch, like copying the archives to a NAS.
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	preBatchHook    = "preBatch"
+	preVersionHook  = "preVersion"
+	postBuildHook   = "postBuild"
+	postArchiveHook = "postArchive"
+	postBatchHook   = "postBatch"
+	onFailureHook   = "onFailure"
+)
+
+// what the hooks are told about the state of the batch in UPR_STATUS
+const (
+	hookStatusRunning   = "running"
+	hookStatusSucceeded = "succeeded"
+	hookStatusFailed    = "failed"
+)
+
+// the hooks run right after a stage succeeded
+var postStageHooks = map[string]string{
+	buildStageName:   postBuildHook,
+	archiveStageName: postArchiveHook,
+}
+
+/*
+Runs the named hook, if the config has one, through the executor. The context is passed in environment variables:
+UPR_HOOK, UPR_STATUS, UPR_PLUGIN_NAME, UPR_OUTPUT_BASE_DIR, and for a version UPR_VERSION, UPR_STAGING_DIR, UPR_OUTPUT_DIR, UPR_ARCHIVE_PATH.
+The release is nil for the hooks of the whole batch.
+*/
+func (pb *PluginBuilder) runHook(ctx context.Context, name string, release *Release, status string, extraEnv ...string) error {
+	command := pb.hookCommand(name)
+	if command == "" {
+		return nil
+	}
+
+	hookLog := pb.log.With("hook", name)
+	if release != nil {
+		hookLog = hookLog.With("version", release.Version)
+	}
+	hookLog.Info("running hook", "command", command)
+
+	hookCmd := pb.runner.CreateHookCommand(command)
+	hookCmd.Env = append(append(os.Environ(), pb.hookEnvironment(name, release, status)...), extraEnv...)
+	flush := forwardOutput(hookCmd, hookLog, "hook", nil)
+	defer flush()
+
+	if err := runCommand(ctx, hookCmd); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
+
+// hooks after a step only warn on failure, what they follow is already done
+func (pb *PluginBuilder) runPostHook(ctx context.Context, name string, release *Release, status string) {
+	if err := pb.runHook(ctx, name, release, status); err != nil {
+		pb.log.Warn("hook failed", "hook", name, "error", err)
+	}
+}
+
+func (pb *PluginBuilder) hookCommand(name string) string {
+	hooks := pb.config.Hooks
+	if hooks == nil {
+		return ""
+	}
+
+	switch name {
+	case preBatchHook:
+		return hooks.PreBatch
+	case preVersionHook:
+		return hooks.PreVersion
+	case postBuildHook:
+		return hooks.PostBuild
+	case postArchiveHook:
+		return hooks.PostArchive
+	case postBatchHook:
+		return hooks.PostBatch
+	case onFailureHook:
+		return hooks.OnFailure
+	}
+	return ""
+}
+
+func (pb *PluginBuilder) hookEnvironment(name string, release *Release, status string) []string {
+	env := []string{
+		"UPR_HOOK=" + name,
+		"UPR_STATUS=" + status,
+		"UPR_PLUGIN_NAME=" + createPluginName(pb.config.PluginPath),
+		"UPR_OUTPUT_BASE_DIR=" + pb.config.OutputBaseDirectory,
+	}
+	if release == nil {
+		return env
+	}
+
+	return append(env,
+		"UPR_VERSION="+release.Version,
+		"UPR_STAGING_DIR="+release.StagingDir,
+		"UPR_OUTPUT_DIR="+release.OutputDir,
+		"UPR_ARCHIVE_PATH="+pb.archivePathOf(release),
+	)
+}
+
+// the archive is in the staging area until the release is published
+func (pb *PluginBuilder) archivePathOf(release *Release) string {
+	if release.StagingDir != "" && isFileIn(pb.fs, release.StagingDir+".zip") {
+		return release.StagingDir + ".zip"
+	}
+	if release.OutputDir == "" {
+		return ""
+	}
+	return release.OutputDir + ".zip"
+}
+
+func failureEnvironment(err error) string {
+	return "UPR_ERROR=" + strings.TrimSpace(err.Error())
+}
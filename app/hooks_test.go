@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+// a hook command that appends the named environment variable to the file
+func appendEnvCommand(variable string, path string) string {
+	if runtime.GOOS == "windows" {
+		return "echo %" + variable + "%>> \"" + path + "\""
+	}
+	return "echo \"$" + variable + "\" >> '" + path + "'"
+}
+
+func TestHooksShouldRunAfterTheirStagesAndBatch(t *testing.T) {
+	// given
+	base := t.TempDir()
+	hookLog := filepath.Join(base, "hooks.log")
+	config := writeTestProject(base, []string{"5.4"}, t)
+	config.Hooks = &model.HooksConfig{
+		PreBatch:    appendEnvCommand("UPR_HOOK", hookLog),
+		PreVersion:  appendEnvCommand("UPR_VERSION", hookLog),
+		PostBuild:   appendEnvCommand("UPR_HOOK", hookLog),
+		PostArchive: appendEnvCommand("UPR_ARCHIVE_PATH", hookLog),
+		PostBatch:   appendEnvCommand("UPR_STATUS", hookLog),
+	}
+	underTest := NewPluginBuilder(config, FakeExecutor{})
+
+	// when
+	if err := underTest.BuildPluginsForSelectedVersions(context.Background(), model.CmdInput{EngineVersions: "5.4", SkipDocs: true}, filepath.Join(base, "script.exe")); err != nil {
+		t.Fatalf("The batch should have succeeded: %v", err)
+	}
+
+	// then
+	data, _ := os.ReadFile(hookLog)
+	actual := strings.Fields(string(data))
+	expected := []string{
+		"preBatch",
+		"5.4",
+		"postBuild",
+		filepath.Join(config.OutputBaseDirectory, model.StagingDirectoryName, "MyPlugin_5.4.zip"),
+		"succeeded",
+	}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("Expected hooks %v, actual %v", expected, actual)
+	}
+}
+
+func TestFailingPreVersionHookShouldOnlySkipItsVersion(t *testing.T) {
+	// given
+	base := t.TempDir()
+	hookLog := filepath.Join(base, "hooks.log")
+	failFor54 := `test "$UPR_VERSION" != 5.4`
+	if runtime.GOOS == "windows" {
+		failFor54 = `if "%UPR_VERSION%"=="5.4" exit 3`
+	}
+	config := writeTestProject(base, []string{"5.3", "5.4", "5.5"}, t)
+	config.Hooks = &model.HooksConfig{
+		PreVersion: failFor54,
+		OnFailure:  appendEnvCommand("UPR_VERSION", hookLog),
+		PostBatch:  appendEnvCommand("UPR_STATUS", hookLog),
+	}
+	underTest := NewPluginBuilder(config, FakeExecutor{})
+
+	// when
+	err := underTest.BuildPluginsForSelectedVersions(context.Background(), model.CmdInput{EngineVersions: "5.3,5.4,5.5", SkipDocs: true}, filepath.Join(base, "script.exe"))
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "skipped 5.4") {
+		t.Errorf("The batch should fail for the skipped 5.4, actual %v", err)
+	}
+	for version, released := range map[string]bool{"5.3": true, "5.4": false, "5.5": true} {
+		if IsPathExist(filepath.Join(config.OutputBaseDirectory, "MyPlugin_"+version)) != released {
+			t.Errorf("UE %s should be released: %v", version, released)
+		}
+	}
+	data, _ := os.ReadFile(hookLog)
+	if expected := []string{"5.4", "failed"}; !slices.Equal(strings.Fields(string(data)), expected) {
+		t.Errorf("Expected hooks %v, actual %v", expected, strings.Fields(string(data)))
+	}
+	versions := underTest.Report().Versions
+	if len(versions) != 3 || versions[1].Version != "5.4" || versions[1].Succeeded {
+		t.Errorf("The report should record 5.4 as failed, actual %+v", versions)
+	}
+}
+
+func TestFailingHookShouldReturnError(t *testing.T) {
+	// given
+	config := model.Config{Hooks: &model.HooksConfig{PreVersion: "exit 3"}}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	err := underTest.runHook(context.Background(), preVersionHook, &Release{Version: "5.4"}, hookStatusRunning)
+
+	// then
+	if err == nil {
+		t.Error("A non-zero exit should fail the hook.")
+	}
+}
+
+func TestMissingHookShouldDoNothing(t *testing.T) {
+	// given
+	underTest := NewPluginBuilder(&model.Config{}, FakeExecutor{})
+
+	// when
+	err := underTest.runHook(context.Background(), onFailureHook, nil, hookStatusFailed)
+
+	// then
+	if err != nil {
+		t.Errorf("A hook that isn't configured should not fail: %v", err)
+	}
+}
+
+func TestHookEnvironmentShouldDescribeRelease(t *testing.T) {
+	// given
+	config := model.Config{OutputBaseDirectory: "Output", PluginPath: filepath.Join("Plugin", "MyPlugin.uplugin")}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+	release := &Release{Version: "5.4", StagingDir: filepath.Join("Output", ".staging", "MyPlugin_5.4"), OutputDir: filepath.Join("Output", "MyPlugin_5.4")}
+
+	// when
+	env := underTest.hookEnvironment(postBuildHook, release, hookStatusRunning)
+
+	// then
+	expected := []string{
+		"UPR_HOOK=postBuild",
+		"UPR_STATUS=running",
+		"UPR_PLUGIN_NAME=MyPlugin",
+		"UPR_OUTPUT_BASE_DIR=Output",
+		"UPR_VERSION=5.4",
+		"UPR_STAGING_DIR=" + release.StagingDir,
+		"UPR_OUTPUT_DIR=" + release.OutputDir,
+		"UPR_ARCHIVE_PATH=" + release.OutputDir + ".zip",
+	}
+	if !arrayContainsAll(expected, env) {
+		t.Errorf("Expected %v in %v", expected, env)
+	}
+}
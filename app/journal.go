@@ -0,0 +1,214 @@
+// the journal of a batch, so a batch interrupted by a crash or a reboot can be resumed where it stopped.
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+/*
+Starts the journal of the batch. With resume, the journal of the interrupted batch is loaded instead,
+which fails if there is none, or if the config, the command or the plugin sources changed since it was written.
+*/
+func (pb *PluginBuilder) startJournal(cmdInput model.CmdInput) error {
+	inputs, err := pb.journalInputs(cmdInput)
+	if err != nil {
+		return fmt.Errorf("failed to fingerprint the inputs of the batch: %w", err)
+	}
+
+	path := pb.journalPath()
+	if !cmdInput.Resume {
+		if pathExists(pb.fs, path) {
+			pb.log.Info("starting over, the journal of an interrupted batch is replaced, --resume would have continued it", "path", path)
+		}
+		pb.journal = &model.BatchJournal{Inputs: inputs}
+		pb.saveJournal()
+		return nil
+	}
+
+	journal, err := readJournal(pb.fs, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("nothing to resume, no journal of an interrupted batch in %s", pb.config.OutputBaseDirectory)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the journal %s: %w", path, err)
+	}
+	if changed := changedInputs(journal.Inputs, inputs); len(changed) > 0 {
+		return fmt.Errorf("can't resume, the %s changed since the batch was interrupted, run it without --resume to start over", strings.Join(changed, " and "))
+	}
+
+	pb.journal = &journal
+	pb.report = journal.Report
+	pb.log.Info("resuming the interrupted batch", "path", path)
+	return nil
+}
+
+// the entry of the version in the journal, added if the batch hasn't reached it yet
+func (pb *PluginBuilder) journalVersion(version string) *model.JournalVersion {
+	for i := range pb.journal.Versions {
+		if pb.journal.Versions[i].Version == version {
+			return &pb.journal.Versions[i]
+		}
+	}
+	pb.journal.Versions = append(pb.journal.Versions, model.JournalVersion{Version: version})
+	return &pb.journal.Versions[len(pb.journal.Versions)-1]
+}
+
+/*
+The stages the version still has to go through. The stages completed by the interrupted batch are only skipped
+while their work is still in the staging directory, otherwise the version starts over, without what was left of it.
+*/
+func (pb *PluginBuilder) remainingStages(stages []Stage, release *Release) []Stage {
+	entry := pb.journalVersion(release.Version)
+	if len(entry.CompletedStages) > 0 && !pathExists(pb.fs, release.StagingDir) {
+		pb.log.Info("the staging folder of the interrupted version is gone, starting it over", "version", release.Version, "path", release.StagingDir)
+		entry.CompletedStages = nil
+	}
+	if len(entry.CompletedStages) == 0 {
+		if release.CmdInput.Resume && pathExists(pb.fs, release.StagingDir) {
+			discardStagedRelease(pb.guard, release.StagingDir)
+		}
+		// the report of an earlier attempt is replaced by the one starting now
+		pb.report.Versions = slices.DeleteFunc(pb.report.Versions, func(report model.VersionReport) bool {
+			return report.Version == release.Version
+		})
+		return stages
+	}
+
+	var remaining []Stage
+	for _, stage := range stages {
+		if !slices.Contains(entry.CompletedStages, stage.Name()) {
+			remaining = append(remaining, stage)
+		}
+	}
+	pb.log.Info("resuming version", "version", release.Version, "completed", strings.Join(entry.CompletedStages, ", "), "stages", strings.Join(stageNames(remaining), " -> "))
+	return remaining
+}
+
+func (pb *PluginBuilder) recordStageCompleted(version string, stage string) {
+	if pb.journal == nil {
+		return
+	}
+	entry := pb.journalVersion(version)
+	entry.CompletedStages = append(entry.CompletedStages, stage)
+	pb.saveJournal()
+}
+
+func (pb *PluginBuilder) recordVersionFinished(version string) {
+	if pb.journal == nil {
+		return
+	}
+	pb.journalVersion(version).Finished = true
+	pb.saveJournal()
+}
+
+// writes the journal, a batch that can't write it still runs, it just can't be resumed
+func (pb *PluginBuilder) saveJournal() {
+	pb.journal.Report = pb.report
+	path := pb.journalPath()
+	data, err := json.MarshalIndent(pb.journal, "", "  ")
+	if err == nil {
+		err = pb.fs.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		pb.log.Warn("failed to write the journal, the batch can't be resumed", "path", path, "error", err)
+		return
+	}
+	markOwned(pb.fs, pb.config.OutputBaseDirectory, model.BatchJournalFile)
+}
+
+// a finished batch has nothing to resume
+func (pb *PluginBuilder) finishJournal() {
+	if pb.journal == nil {
+		return
+	}
+	pb.journal = nil
+	pb.guard.Remove(pb.journalPath())
+}
+
+func (pb *PluginBuilder) journalPath() string {
+	return filepath.Join(pb.config.OutputBaseDirectory, model.BatchJournalFile)
+}
+
+func readJournal(fsys FileSystem, path string) (model.BatchJournal, error) {
+	journal := model.BatchJournal{}
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return journal, err
+	}
+	err = json.Unmarshal(data, &journal)
+	return journal, err
+}
+
+/*
+Fingerprints the effective config, the command without --resume, and the plugin sources by the path, size and modification time of their files.
+The folders the build creates in the plugin, like Binaries and Intermediate, and the output directory are left out.
+*/
+func (pb *PluginBuilder) journalInputs(cmdInput model.CmdInput) (model.JournalInputs, error) {
+	cmdInput.Resume = false
+	configDigest, err := digestJSON(pb.config)
+	if err != nil {
+		return model.JournalInputs{}, err
+	}
+	commandDigest, err := digestJSON(cmdInput)
+	if err != nil {
+		return model.JournalInputs{}, err
+	}
+
+	pluginDir := filepath.Dir(pb.config.PluginPath)
+	unneeded := pb.getUnneededFolders()
+	hash := sha256.New()
+	err = walkDirectory(pb.fs, pluginDir, func(path string, entry fs.DirEntry) error {
+		if entry.IsDir() {
+			if slices.Contains(unneeded, entry.Name()) || strings.HasPrefix(entry.Name(), ".") || IsPathEqual(path, pb.config.OutputBaseDirectory) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relative, _ := filepath.Rel(pluginDir, path)
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\n", filepath.ToSlash(relative), info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return model.JournalInputs{}, err
+	}
+
+	return model.JournalInputs{Config: configDigest, Command: commandDigest, Plugin: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+func digestJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// what the inputs of the journal differ in, in words
+func changedInputs(journaled model.JournalInputs, current model.JournalInputs) []string {
+	var changed []string
+	if journaled.Config != current.Config {
+		changed = append(changed, "config")
+	}
+	if journaled.Command != current.Command {
+		changed = append(changed, "command line")
+	}
+	if journaled.Plugin != current.Plugin {
+		changed = append(changed, "plugin sources")
+	}
+	return changed
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+// a stage recording the versions it ran for, failing or crashing on the ones set
+type probeStage struct {
+	ran     []string
+	failFor string
+	crashOn string
+}
+
+// builder options adding the probe as the probe stage
+func (probe *probeStage) options() BuilderOptions {
+	return BuilderOptions{Stages: map[string]StageFactory{"probe": func(pb *PluginBuilder, options json.RawMessage) (Stage, error) {
+		return funcStage{name: "probe", run: func(ctx context.Context, release *Release) error {
+			probe.ran = append(probe.ran, release.Version)
+			if release.Version == probe.crashOn {
+				panic("machine rebooted")
+			}
+			if release.Version == probe.failFor {
+				return errors.New("probe failed")
+			}
+			return nil
+		}}, nil
+	}}}
+}
+
+// the stages of the journal tests, with the probe between the build and the publish
+var probeStages = []model.StageConfig{{Name: "build"}, {Name: "probe"}, {Name: "publish"}}
+
+func TestResumeShouldSkipTheVersionsAlreadyReleased(t *testing.T) {
+	// given
+	probe := &probeStage{failFor: "5.4"}
+	base := t.TempDir()
+	config := writeTestProject(base, []string{"5.3", "5.4"}, t)
+	config.Stages = probeStages
+	execPath := filepath.Join(base, "script.exe")
+	input := model.CmdInput{EngineVersions: "5.3,5.4", SkipDocs: true}
+	if err := NewPluginBuilderWithOptions(config, FakeExecutor{}, probe.options()).BuildPluginsForSelectedVersions(context.Background(), input, execPath); err == nil {
+		t.Fatal("The first batch should fail on 5.4.")
+	}
+	probe.ran, probe.failFor = nil, ""
+
+	// when
+	input.Resume = true
+	underTest := NewPluginBuilderWithOptions(config, FakeExecutor{}, probe.options())
+	err := underTest.BuildPluginsForSelectedVersions(context.Background(), input, execPath)
+
+	// then
+	if err != nil {
+		t.Fatalf("The resumed batch should succeed: %v", err)
+	}
+	if !slices.Equal(probe.ran, []string{"5.4"}) {
+		t.Errorf("Only the failed version should run again, ran %v", probe.ran)
+	}
+	if versions := underTest.Report().Versions; len(versions) != 2 || versions[0].Version != "5.3" || versions[1].Version != "5.4" {
+		t.Errorf("The report should cover the whole batch, actual %+v", versions)
+	}
+	if IsPathExist(filepath.Join(config.OutputBaseDirectory, model.BatchJournalFile)) {
+		t.Error("The journal should be removed once the batch succeeded.")
+	}
+}
+
+func TestResumeShouldContinueAtTheFirstIncompleteStage(t *testing.T) {
+	// given
+	probe := &probeStage{crashOn: "5.4"}
+	base := t.TempDir()
+	config := writeTestProject(base, []string{"5.3", "5.4"}, t)
+	config.Stages = probeStages
+	execPath := filepath.Join(base, "script.exe")
+	input := model.CmdInput{EngineVersions: "5.3,5.4", SkipDocs: true}
+	func() {
+		defer func() { recover() }()
+		NewPluginBuilderWithOptions(config, FakeExecutor{}, probe.options()).BuildPluginsForSelectedVersions(context.Background(), input, execPath)
+	}()
+	probe.crashOn = ""
+
+	// when
+	var started []string
+	input.Resume = true
+	options := probe.options()
+	options.OnEvent = func(event model.Event) {
+		if stage, ok := event.(model.StageStarted); ok {
+			started = append(started, stage.Version+" "+stage.Stage)
+		}
+	}
+	underTest := NewPluginBuilderWithOptions(config, FakeExecutor{}, options)
+	err := underTest.BuildPluginsForSelectedVersions(context.Background(), input, execPath)
+
+	// then
+	if err != nil {
+		t.Fatalf("The resumed batch should succeed: %v", err)
+	}
+	if expected := []string{"5.4 probe", "5.4 publish"}; !slices.Equal(started, expected) {
+		t.Errorf("Expected stages %v, actual %v", expected, started)
+	}
+	if !IsPathExist(filepath.Join(config.OutputBaseDirectory, "MyPlugin_5.4")) {
+		t.Error("The interrupted version should be released.")
+	}
+}
+
+func TestResumeShouldRefuseChangedInputs(t *testing.T) {
+	// given
+	probe := &probeStage{failFor: "5.4"}
+	base := t.TempDir()
+	config := writeTestProject(base, []string{"5.3", "5.4"}, t)
+	config.Stages = probeStages
+	execPath := filepath.Join(base, "script.exe")
+	input := model.CmdInput{EngineVersions: "5.3,5.4", SkipDocs: true}
+	if err := NewPluginBuilderWithOptions(config, FakeExecutor{}, probe.options()).BuildPluginsForSelectedVersions(context.Background(), input, execPath); err == nil {
+		t.Fatal("The first batch should fail on 5.4.")
+	}
+	makeFile(filepath.Dir(config.PluginPath), "Added.cpp", t)
+	probe.ran = nil
+
+	// when
+	input.Resume = true
+	err := NewPluginBuilderWithOptions(config, FakeExecutor{}, probe.options()).BuildPluginsForSelectedVersions(context.Background(), input, execPath)
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "plugin sources changed") {
+		t.Errorf("Changed plugin sources should be refused, actual %v", err)
+	}
+	if len(probe.ran) > 0 {
+		t.Errorf("Nothing should run, ran %v", probe.ran)
+	}
+}
+
+func TestResumeWithoutJournalShouldFail(t *testing.T) {
+	// given
+	probe := &probeStage{}
+	base := t.TempDir()
+	config := writeTestProject(base, []string{"5.3", "5.4"}, t)
+	config.Stages = probeStages
+	execPath := filepath.Join(base, "script.exe")
+	input := model.CmdInput{EngineVersions: "5.4", SkipDocs: true, Resume: true}
+
+	// when
+	err := NewPluginBuilderWithOptions(config, FakeExecutor{}, probe.options()).BuildPluginsForSelectedVersions(context.Background(), input, execPath)
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "nothing to resume") {
+		t.Errorf("Resuming without a journal should fail, actual %v", err)
+	}
+}
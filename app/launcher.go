@@ -0,0 +1,106 @@
+// reads the Epic Launcher's LauncherInstalled.dat, which records where the launcher installed the engines.
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+const engineSourceLauncher = "launcher"
+
+// launcher app names of engines, like UE_5.3
+var launcherEngineAppName = regexp.MustCompile(`^UE_(\d+\.\d+)$`)
+
+/*
+Locates the engines installed by the Epic Launcher, from its LauncherInstalled.dat file.
+A missing file means no launcher engines, unless the path was set explicitly in the config.
+*/
+type LauncherEngineLocator struct {
+	Path            string
+	BuildScriptPath string
+	Explicit        bool
+	// the OS file system if nil
+	FileSystem FileSystem
+}
+
+// the json structure of LauncherInstalled.dat
+type launcherInstalled struct {
+	InstallationList []launcherInstallation `json:"InstallationList"`
+}
+
+type launcherInstallation struct {
+	InstallLocation string `json:"InstallLocation"`
+	AppName         string `json:"AppName"`
+	AppVersion      string `json:"AppVersion"`
+}
+
+func (l LauncherEngineLocator) LocateEngines() ([]model.EngineInstall, error) {
+	if l.Path == "" {
+		return nil, nil
+	}
+
+	fsys := l.FileSystem
+	if fsys == nil {
+		fsys = OSFileSystem{}
+	}
+	data, err := fsys.ReadFile(l.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !l.Explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", l.Path, err)
+	}
+
+	var installed launcherInstalled
+	if err := json.Unmarshal(data, &installed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", l.Path, err)
+	}
+
+	var installs []model.EngineInstall
+	for _, installation := range installed.InstallationList {
+		match := launcherEngineAppName.FindStringSubmatch(strings.TrimSpace(installation.AppName))
+		if match == nil || installation.InstallLocation == "" {
+			continue
+		}
+
+		installs = append(installs, model.EngineInstall{
+			Version:         match[1],
+			Root:            installation.InstallLocation,
+			BuildScriptPath: filepath.Join(installation.InstallLocation, l.BuildScriptPath),
+			Source:          engineSourceLauncher,
+		})
+	}
+	return installs, nil
+}
+
+func launcherInstalledPathFor(config *model.Config) string {
+	if config.LauncherInstalledPath != "" {
+		return config.LauncherInstalledPath
+	}
+	return defaultLauncherInstalledPath()
+}
+
+// where the Epic Launcher keeps LauncherInstalled.dat, empty on platforms without a launcher
+func defaultLauncherInstalledPath() string {
+	switch runtime.GOOS {
+	case "windows":
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		return filepath.Join(programData, "Epic", "UnrealEngineLauncher", "LauncherInstalled.dat")
+	case "darwin":
+		return filepath.Join("/Users", "Shared", "Epic Games", "UnrealEngineLauncher", "LauncherInstalled.dat")
+	default:
+		return ""
+	}
+}
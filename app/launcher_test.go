@@ -0,0 +1,76 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestLauncherLocatorShouldReadEnginesFromFixture(t *testing.T) {
+	// given
+	buildScript := filepath.Join("Engine", "Build", "BatchFiles", "RunUAT.bat")
+	underTest := LauncherEngineLocator{Path: filepath.Join("testdata", "LauncherInstalled.dat"), BuildScriptPath: buildScript}
+
+	// when
+	actual, err := underTest.LocateEngines()
+
+	// then
+	if err != nil {
+		t.Fatalf("The fixture should be parsed: %v", err)
+	}
+	if len(actual) != 2 {
+		t.Fatalf("Expected the two engines without the plugin, got %+v", actual)
+	}
+	if actual[0].Version != "5.3" || actual[1].Version != "5.4" || actual[1].Root != `D:\Engines\UE_5.4` {
+		t.Errorf("Unexpected engines: %+v", actual)
+	}
+	if actual[0].BuildScriptPath != filepath.Join(actual[0].Root, buildScript) || actual[0].Source != engineSourceLauncher {
+		t.Errorf("Unexpected build script or source: %+v", actual[0])
+	}
+}
+
+func TestLauncherLocatorShouldIgnoreMissingDefaultFile(t *testing.T) {
+	// given
+	underTest := LauncherEngineLocator{Path: filepath.Join(t.TempDir(), "LauncherInstalled.dat")}
+
+	// when
+	actual, err := underTest.LocateEngines()
+
+	// then
+	if err != nil || len(actual) != 0 {
+		t.Errorf("A missing default file should mean no engines, got %+v (%v)", actual, err)
+	}
+}
+
+func TestLauncherLocatorShouldReportMissingExplicitFile(t *testing.T) {
+	// given
+	underTest := LauncherEngineLocator{Path: filepath.Join(t.TempDir(), "LauncherInstalled.dat"), Explicit: true}
+
+	// when
+	_, err := underTest.LocateEngines()
+
+	// then
+	if err == nil {
+		t.Error("A missing file set in the config should be reported.")
+	}
+}
+
+func TestLauncherEnginesShouldBeOverriddenByBaseDirectory(t *testing.T) {
+	// given
+	base := t.TempDir()
+	writeBuildScript(base, "5.3", "RunUAT.bat", t)
+	config := model.Config{
+		EngineBaseDirectory:   base,
+		BuildScriptPath:       "RunUAT.bat",
+		LauncherInstalledPath: filepath.Join("testdata", "LauncherInstalled.dat"),
+	}
+
+	// when
+	actual := NewEngineResolver(&config).FindEngines()
+
+	// then
+	if len(actual) != 2 || actual[0].Source != engineSourceBaseDirectory || actual[1].Source != engineSourceLauncher {
+		t.Errorf("Expected 5.3 from the base directory and 5.4 from the launcher, got %+v", actual)
+	}
+}
@@ -0,0 +1,78 @@
+// where the application writes what it's doing, as structured records, and how the output of its subprocesses joins them.
+package app
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+// the logger used when none is given, slog's default one
+func defaultLogger() *slog.Logger {
+	return slog.Default()
+}
+
+// discards every record, for programs that only want the events
+func NewDiscardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+/*
+Writes what a subprocess prints as one record per line, tagged with where it came from, e.g. subprocess=uat stream=stderr.
+The last line is only written by Flush if it doesn't end in a newline.
+*/
+type lineWriter struct {
+	log     *slog.Logger
+	pending []byte
+}
+
+func newLineWriter(logger *slog.Logger, subprocess string, stream string, attrs ...any) *lineWriter {
+	return &lineWriter{log: logger.With(append([]any{"subprocess", subprocess, "stream", stream}, attrs...)...)}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		end := bytes.IndexByte(w.pending, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		w.writeLine(w.pending[:end])
+		w.pending = w.pending[end+1:]
+	}
+}
+
+func (w *lineWriter) Flush() {
+	if len(w.pending) > 0 {
+		w.writeLine(w.pending)
+		w.pending = nil
+	}
+}
+
+func (w *lineWriter) writeLine(line []byte) {
+	text := strings.TrimRight(string(line), "\r")
+	if strings.TrimSpace(text) != "" {
+		w.log.Info(text)
+	}
+}
+
+/*
+Routes the command's output to the logger line by line, and to the extra writers, like the build log, as it is.
+The returned function flushes the unfinished lines, it's called once the command is done.
+*/
+func forwardOutput(cmd *exec.Cmd, logger *slog.Logger, subprocess string, extra io.Writer, attrs ...any) func() {
+	stdout := newLineWriter(logger, subprocess, "stdout", attrs...)
+	stderr := newLineWriter(logger, subprocess, "stderr", attrs...)
+	if extra == nil {
+		cmd.Stdout, cmd.Stderr = stdout, stderr
+	} else {
+		cmd.Stdout, cmd.Stderr = io.MultiWriter(stdout, extra), io.MultiWriter(stderr, extra)
+	}
+
+	return func() {
+		stdout.Flush()
+		stderr.Flush()
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLineWriterShouldWriteARecordPerLine(t *testing.T) {
+	// given
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, nil))
+	underTest := newLineWriter(logger, "uat", "stdout", "version", "5.4")
+
+	// when
+	underTest.Write([]byte("Building MyPlugin...\r\nCompiling "))
+	underTest.Write([]byte("Module.cpp\n\n"))
+	underTest.Write([]byte("BUILD SUCCESSFUL"))
+	underTest.Flush()
+
+	// then
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	expected := []string{"Building MyPlugin...", "Compiling Module.cpp", "BUILD SUCCESSFUL"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d records, got %q", len(expected), lines)
+	}
+
+	for i, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid record %q: %v", line, err)
+		}
+		if record["msg"] != expected[i] {
+			t.Errorf("Expected the message %q, got %q", expected[i], record["msg"])
+		}
+		if record["subprocess"] != "uat" || record["stream"] != "stdout" || record["version"] != "5.4" {
+			t.Errorf("The record should be tagged with its source, got %v", record)
+		}
+	}
+}
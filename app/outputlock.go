@@ -0,0 +1,195 @@
+// locks the output directory for a whole run, so two runs don't overwrite each other's releases.
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"unreal-plugin-release/model"
+)
+
+// how often a running batch refreshes its lock
+const lockHeartbeatInterval = time.Minute
+
+// a lock not refreshed for this long belongs to a run that is gone, even on another machine
+const lockStaleAfter = 10 * time.Minute
+
+// the run holding the lock of an output directory
+type LockHolder struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	User      string    `json:"user"`
+	StartedAt time.Time `json:"startedAt"`
+	// refreshed while the run is alive
+	Heartbeat time.Time `json:"heartbeat"`
+}
+
+// the output directory is locked by another run
+type LockedError struct {
+	Path   string
+	Holder LockHolder
+}
+
+func (e *LockedError) Error() string {
+	if e.Holder.PID == 0 {
+		return fmt.Sprintf("the output directory is locked by another run, remove %s if no run is using it", e.Path)
+	}
+	return fmt.Sprintf("the output directory is locked by %s on %s (pid %d) since %s, remove %s if that run is gone",
+		e.Holder.User, e.Holder.Host, e.Holder.PID, e.Holder.StartedAt.Local().Format(time.DateTime), e.Path)
+}
+
+// the lock of the output directory, held until released
+type outputLock struct {
+	fs     FileSystem
+	path   string
+	holder LockHolder
+	log    *slog.Logger
+	stop   chan struct{}
+	done   sync.WaitGroup
+}
+
+/*
+Takes the lock of the output directory, failing with a LockedError if another run holds it.
+A lock left behind by a run that is gone, because its process no longer runs on this machine or it stopped refreshing the lock,
+is removed and taken over.
+*/
+func acquireOutputLock(fsys FileSystem, outputDir string, log *slog.Logger) (*outputLock, error) {
+	path := filepath.Join(outputDir, model.OutputLockFile)
+	now := time.Now()
+	holder := LockHolder{PID: os.Getpid(), Host: hostName(), User: userName(), StartedAt: now, Heartbeat: now}
+	data, err := json.MarshalIndent(holder, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := createExclusive(fsys, path, data)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to lock the output directory: %w", err)
+		}
+
+		existing, staleReason := inspectLock(fsys, path, holder.Host, now)
+		if staleReason == "" || attempt > 0 {
+			return nil, &LockedError{Path: path, Holder: existing}
+		}
+		log.Warn("removing stale lock", "path", path, "user", existing.User, "host", existing.Host, "pid", existing.PID, "reason", staleReason)
+		if err := fsys.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove the stale lock: %w", err)
+		}
+	}
+
+	lock := &outputLock{fs: fsys, path: path, holder: holder, log: log, stop: make(chan struct{})}
+	lock.done.Add(1)
+	go lock.keepAlive()
+	return lock, nil
+}
+
+/*
+Reads the lock, returning its holder and why it's stale, or an empty reason while its run is alive.
+A lock that can't be read is being written right now, unless it's older than a heartbeat would allow.
+*/
+func inspectLock(fsys FileSystem, path string, host string, now time.Time) (LockHolder, string) {
+	var holder LockHolder
+	data, err := fsys.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, &holder)
+	}
+	if err != nil || holder.PID == 0 {
+		if info, statErr := fsys.Stat(path); statErr == nil && now.Sub(info.ModTime()) > lockStaleAfter {
+			return LockHolder{}, "unreadable and not refreshed since " + info.ModTime().Local().Format(time.DateTime)
+		}
+		return LockHolder{}, ""
+	}
+
+	switch {
+	case holder.Host == host && !isProcessRunning(holder.PID):
+		return holder, fmt.Sprintf("process %d no longer runs", holder.PID)
+	case now.Sub(holder.Heartbeat) > lockStaleAfter:
+		return holder, "not refreshed since " + holder.Heartbeat.Local().Format(time.DateTime)
+	}
+	return holder, ""
+}
+
+// refreshes the heartbeat of the lock until it's released
+func (l *outputLock) keepAlive() {
+	defer l.done.Done()
+	ticker := time.NewTicker(lockHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case tick := <-ticker.C:
+			l.holder.Heartbeat = tick
+			data, err := json.MarshalIndent(l.holder, "", "  ")
+			if err == nil {
+				err = l.fs.WriteFile(l.path, data, 0644)
+			}
+			if err != nil {
+				l.log.Warn("failed to refresh the lock", "path", l.path, "error", err)
+			}
+		}
+	}
+}
+
+// releases the lock, leaving it alone if another run took it over in the meantime
+func (l *outputLock) release() {
+	close(l.stop)
+	l.done.Wait()
+
+	var current LockHolder
+	data, err := l.fs.ReadFile(l.path)
+	if err != nil || json.Unmarshal(data, &current) != nil {
+		return
+	}
+	if current.PID != l.holder.PID || current.Host != l.holder.Host || !current.StartedAt.Equal(l.holder.StartedAt) {
+		l.log.Warn("the lock was taken over by another run", "path", l.path, "user", current.User, "host", current.Host, "pid", current.PID)
+		return
+	}
+	if err := l.fs.Remove(l.path); err != nil {
+		l.log.Warn("failed to release the lock", "path", l.path, "error", err)
+	}
+}
+
+func hostName() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return name
+}
+
+func userName() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	for _, variable := range []string{"USERNAME", "USER"} {
+		if name := os.Getenv(variable); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+/*
+Locks the output directory for the whole run, the returned function releases it.
+*/
+func (pb *PluginBuilder) lockOutputDirectory() (func(), error) {
+	lock, err := acquireOutputLock(pb.fs, pb.config.OutputBaseDirectory, pb.log)
+	if err != nil {
+		return nil, err
+	}
+	pb.log.Debug("output directory locked", "path", lock.path)
+	return lock.release, nil
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"unreal-plugin-release/model"
+)
+
+func writeLock(dir string, holder LockHolder, t *testing.T) string {
+	data, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, model.OutputLockFile)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSecondLockShouldFailNamingTheHolder(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	first, err := acquireOutputLock(OSFileSystem{}, dir, NewDiscardLogger())
+	if err != nil {
+		t.Fatalf("The first lock should be taken: %v", err)
+	}
+
+	// when
+	_, err = acquireOutputLock(OSFileSystem{}, dir, NewDiscardLogger())
+
+	// then
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("The second lock should fail with a LockedError, actual %v", err)
+	}
+	if locked.Holder.PID != os.Getpid() || locked.Holder.Host != hostName() || !strings.Contains(err.Error(), locked.Holder.User) {
+		t.Errorf("The error should name the holder, actual %v", err)
+	}
+
+	first.release()
+	if IsPathExist(filepath.Join(dir, model.OutputLockFile)) {
+		t.Error("The lock file should be removed on release.")
+	}
+	if second, err := acquireOutputLock(OSFileSystem{}, dir, NewDiscardLogger()); err != nil {
+		t.Errorf("The lock should be taken after the release: %v", err)
+	} else {
+		second.release()
+	}
+}
+
+func TestStaleLockShouldBeTakenOver(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		holder LockHolder
+	}{
+		// beyond the largest process id of every OS
+		{"process gone", LockHolder{PID: 1 << 30, Host: hostName(), User: "someone", StartedAt: now, Heartbeat: now}},
+		{"no heartbeat", LockHolder{PID: 1234, Host: "other-machine", User: "someone", StartedAt: now.Add(-time.Hour), Heartbeat: now.Add(-time.Hour)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			dir := t.TempDir()
+			path := writeLock(dir, test.holder, t)
+
+			// when
+			lock, err := acquireOutputLock(OSFileSystem{}, dir, NewDiscardLogger())
+
+			// then
+			if err != nil {
+				t.Fatalf("The stale lock should be taken over: %v", err)
+			}
+			defer lock.release()
+			holder, _ := inspectLock(OSFileSystem{}, path, hostName(), time.Now())
+			if holder.PID != os.Getpid() {
+				t.Errorf("The lock should be held by this process, actual %+v", holder)
+			}
+		})
+	}
+}
+
+func TestLiveLockOfAnotherMachineShouldBeKept(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	now := time.Now()
+	writeLock(dir, LockHolder{PID: 1234, Host: "other-machine", User: "someone", StartedAt: now, Heartbeat: now}, t)
+
+	// when
+	_, err := acquireOutputLock(OSFileSystem{}, dir, NewDiscardLogger())
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "someone on other-machine (pid 1234)") {
+		t.Errorf("The lock of the other machine should be kept, actual %v", err)
+	}
+}
+
+func TestBatchShouldFailWhileTheOutputDirectoryIsLocked(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := writeTestProject(base, []string{"5.4"}, t)
+	lock, err := acquireOutputLock(OSFileSystem{}, config.OutputBaseDirectory, NewDiscardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	underTest := NewPluginBuilder(config, FakeExecutor{})
+	input := model.CmdInput{EngineVersions: "5.4", SkipDocs: true}
+
+	// when
+	err = underTest.BuildPluginsForSelectedVersions(context.Background(), input, filepath.Join(base, "script.exe"))
+
+	// then
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("The batch should fail on the lock, actual %v", err)
+	}
+	if IsPathExist(filepath.Join(config.OutputBaseDirectory, "MyPlugin_5.4")) {
+		t.Error("Nothing should be built while locked.")
+	}
+
+	lock.release()
+	if err := underTest.BuildPluginsForSelectedVersions(context.Background(), input, filepath.Join(base, "script.exe")); err != nil {
+		t.Fatalf("The batch should succeed once unlocked: %v", err)
+	}
+	if IsPathExist(filepath.Join(config.OutputBaseDirectory, model.OutputLockFile)) {
+		t.Error("The batch should release its lock.")
+	}
+}
@@ -0,0 +1,157 @@
+// names the release folders and archives from the outputNameTemplate of the config.
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"unreal-plugin-release/model"
+)
+
+// characters that are not allowed in file names on at least one platform
+var illegalFileNameCharacters = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]`)
+
+// names that Windows reserves for devices, with or without an extension
+var reservedFileNames = regexp.MustCompile(`(?i)^(CON|PRN|AUX|NUL|COM[1-9]|LPT[1-9])(\..*)?$`)
+
+var outputNameFunctions = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// example values to check a template with before anything is built
+var sampleOutputNameData = model.OutputNameData{
+	PluginName:         "MyPlugin",
+	PluginVersion:      "1.0.0",
+	EngineVersion:      "5.3",
+	EnginePatch:        2,
+	ExactEngineVersion: "5.3.2",
+	Platforms:          []string{"Win64"},
+	Date:               "2025-01-31",
+	GitCommit:          "0123abc",
+	Profile:            "release",
+}
+
+// the sample of another engine version, with the same patch, as the patch alone doesn't tell the versions apart
+var otherSampleOutputNameData = func() model.OutputNameData {
+	data := sampleOutputNameData
+	data.EngineVersion, data.ExactEngineVersion = "5.4", "5.4.2"
+	return data
+}()
+
+/*
+Checks that the template parses, that it renders a valid file name, and that it names the versions differently,
+otherwise every version would replace the release of the previous one.
+*/
+func ValidateOutputNameTemplate(nameTemplate string) error {
+	if nameTemplate == "" {
+		return nil
+	}
+
+	name, err := renderOutputName(nameTemplate, sampleOutputNameData)
+	if err != nil {
+		return err
+	}
+	otherName, err := renderOutputName(nameTemplate, otherSampleOutputNameData)
+	if err != nil {
+		return err
+	}
+	if name == otherName {
+		return fmt.Errorf("outputNameTemplate renders the same name %q for every engine version, use .EngineVersion or .ExactEngineVersion in it", name)
+	}
+	return nil
+}
+
+func renderOutputName(nameTemplate string, data model.OutputNameData) (string, error) {
+	parsed, err := template.New("outputNameTemplate").Funcs(outputNameFunctions).Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid outputNameTemplate: %w", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := parsed.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("invalid outputNameTemplate: %w", err)
+	}
+
+	name := rendered.String()
+	if err := validateFileName(name); err != nil {
+		return "", fmt.Errorf("outputNameTemplate renders an invalid name %q: %w", name, err)
+	}
+	return name, nil
+}
+
+func validateFileName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("the name is empty")
+	}
+	if illegalFileNameCharacters.MatchString(name) {
+		return errors.New("the name contains characters that are not allowed in file names")
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return errors.New("the name cannot end with a dot or a space")
+	}
+	if reservedFileNames.MatchString(name) {
+		return errors.New("the name is reserved by Windows")
+	}
+	return nil
+}
+
+/*
+The name of the release folder of the version, and of its archive without the .zip extension.
+Without a template, it's <PluginName>_<version>.
+*/
+func (pb *PluginBuilder) outputNameFor(version string, buildVersion *model.EngineBuildVersion, cmdInput model.CmdInput) (string, error) {
+	pluginName := createPluginName(pb.config.PluginPath)
+	if pb.config.OutputNameTemplate == "" {
+		return pluginName + "_" + version, nil
+	}
+
+	return renderOutputName(pb.config.OutputNameTemplate, pb.createOutputNameData(version, buildVersion, cmdInput))
+}
+
+func (pb *PluginBuilder) createOutputNameData(version string, buildVersion *model.EngineBuildVersion, cmdInput model.CmdInput) model.OutputNameData {
+	exactVersion := exactEngineVersion(version, buildVersion)
+	patch := 0
+	if parsed, err := parseEngineVersion(exactVersion); err == nil {
+		patch = parsed[2]
+	}
+
+	return model.OutputNameData{
+		PluginName:         createPluginName(pb.config.PluginPath),
+		PluginVersion:      readPluginVersionName(pb.fs, pb.config.PluginPath),
+		EngineVersion:      version,
+		EnginePatch:        patch,
+		ExactEngineVersion: exactVersion,
+		Platforms:          pb.targetPlatformsFor(version, cmdInput),
+		Date:               pb.now().Format("2006-01-02"),
+		GitCommit:          pb.gitCommit(),
+		Profile:            cmdInput.Profile,
+	}
+}
+
+// the short hash of the commit the plugin source is at, empty if it's not in a git repository
+func (pb *PluginBuilder) gitCommit() string {
+	output, err := pb.runner.CreateGitCommitCommand(filepath.Dir(pb.config.PluginPath)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// the VersionName of the .uplugin, empty if it cannot be read
+func readPluginVersionName(fsys FileSystem, pluginPath string) string {
+	descriptor, err := readPluginDescriptor(fsys, pluginPath)
+	if err != nil {
+		return ""
+	}
+
+	var versionName string
+	descriptor.getValue("VersionName", &versionName)
+	return versionName
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"unreal-plugin-release/model"
+)
+
+type outputNameTemplateTestData struct {
+	template string
+	valid    bool
+}
+
+func TestRenderOutputName(t *testing.T) {
+	// given
+	nameTemplate := `{{.PluginName}}_v{{.PluginVersion}}_UE{{.EngineVersion}}_{{join .Platforms "+"}}`
+
+	// when
+	actual, err := renderOutputName(nameTemplate, sampleOutputNameData)
+
+	// then
+	if err != nil || actual != "MyPlugin_v1.0.0_UE5.3_Win64" {
+		t.Errorf("Expected MyPlugin_v1.0.0_UE5.3_Win64, got %q (%v)", actual, err)
+	}
+}
+
+func TestValidateOutputNameTemplate(t *testing.T) {
+	for i, tt := range createOutputNameTemplateTestData() {
+		t.Run("OutputNameTemplateTest #"+strconv.Itoa(i), func(t *testing.T) {
+			// when
+			err := ValidateOutputNameTemplate(tt.template)
+
+			// then
+			if tt.valid != (err == nil) {
+				t.Errorf("%q: expected valid: %v, error: %v", tt.template, tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateOutputNameTemplateShouldRejectTheSameNameForEveryVersion(t *testing.T) {
+	// when
+	err := ValidateOutputNameTemplate("{{.PluginName}}_{{.PluginVersion}}")
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "same name") {
+		t.Errorf("A template without the engine version should be rejected, got %v", err)
+	}
+}
+
+func TestOutputNameForWithoutTemplateShouldBePluginNameAndVersion(t *testing.T) {
+	// given
+	config := model.Config{PluginPath: filepath.Join(t.TempDir(), "MyPlugin.uplugin")}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	actual, err := underTest.outputNameFor("5.5", nil, model.CmdInput{})
+
+	// then
+	if err != nil || actual != "MyPlugin_5.5" {
+		t.Errorf("Expected MyPlugin_5.5, got %q (%v)", actual, err)
+	}
+}
+
+func TestOutputNameForShouldUseDescriptorEngineAndProfile(t *testing.T) {
+	// given
+	config := model.Config{
+		PluginPath:         writeDescriptor(t.TempDir(), testDescriptor, t),
+		TargetPlatforms:    []string{"Win64", "Linux"},
+		OutputNameTemplate: `{{.PluginName}}_v{{.PluginVersion}}_UE{{.ExactEngineVersion}}_{{join .Platforms "+"}}_{{.Profile}}_{{.Date}}`,
+	}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+	underTest.now = func() time.Time { return time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC) }
+
+	// when
+	actual, err := underTest.outputNameFor("5.3", &model.EngineBuildVersion{MajorVersion: 5, MinorVersion: 3, PatchVersion: 2}, model.CmdInput{Profile: "fab"})
+
+	// then
+	expected := "MyPlugin_v1.4.2_UE5.3.2_Win64+Linux_fab_2025-03-14"
+	if err != nil || actual != expected {
+		t.Errorf("Expected %q, got %q (%v)", expected, actual, err)
+	}
+}
+
+// test data
+func createOutputNameTemplateTestData() []outputNameTemplateTestData {
+	return []outputNameTemplateTestData{
+		{"", true},
+		{"{{.PluginName}}_{{.EngineVersion}}", true},
+		{"{{.PluginName}}_{{.GitCommit}}_{{.Date}}_UE{{.ExactEngineVersion}}", true},
+		{"{{.PluginName}}_{{.PluginVersion}}", false},
+		{"{{.PluginName}}_{{.EnginePatch}}", false},
+		{"{{.PluginName", false},
+		{"{{.Unknown}}", false},
+		{"{{.PluginName}}/{{.EngineVersion}}", false},
+		{"{{.PluginName}}:{{.EngineVersion}}", false},
+		{"{{.PluginName}}.", false},
+		{"CON", false},
+		{"   ", false},
+	}
+}
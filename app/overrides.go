@@ -0,0 +1,297 @@
+// overrides the fields of config.json from the environment and the command line, telling where each value came from.
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"unreal-plugin-release/model"
+)
+
+// the prefix of the environment variables overriding the config
+const configEnvPrefix = "UPR_"
+
+// the fields that describe the config file itself, instead of a setting
+var notOverridableFields = map[string]bool{"$schema": true, "profiles": true}
+
+// how the value of an overridden field is written
+const (
+	// as it is
+	FieldFormatString = "string"
+	// comma separated, or a json array
+	FieldFormatList = "list"
+	// as json, like in config.json
+	FieldFormatJSON = "json"
+)
+
+// a field of the config that can be overridden, with the flag and the environment variable doing it
+type ConfigField struct {
+	// the json name, like outputBaseDirectory
+	Name string
+	// the name of the flag without dashes, like output-base-directory
+	Flag string
+	// like UPR_OUTPUT_BASE_DIRECTORY
+	Env         string
+	Format      string
+	Description string
+}
+
+// where the effective value of a config field came from
+type ConfigSource string
+
+// the layers of the config, each one overriding the ones before it
+const (
+	SourceDefault ConfigSource = "default"
+	SourceFile    ConfigSource = "file"
+	SourceProfile ConfigSource = "profile"
+	SourceEnv     ConfigSource = "env"
+	SourceFlag    ConfigSource = "flag"
+)
+
+// what is layered over config.json, in the order of precedence
+type ConfigLayers struct {
+	Profile string
+	// values by field name, from the environment variables
+	Env map[string]string
+	// values by field name, from the flags
+	Flags map[string]string
+}
+
+/*
+The fields of the config that can be overridden, in the order of model.Config.
+Every field is, except $schema and the profiles themselves.
+*/
+func OverridableConfigFields() []ConfigField {
+	configType := reflect.TypeOf(model.Config{})
+	var fields []ConfigField
+	for i := 0; i < configType.NumField(); i++ {
+		name := jsonFieldName(configType.Field(i))
+		if name == "" || notOverridableFields[name] {
+			continue
+		}
+		words := splitCamelCase(name)
+		fields = append(fields, ConfigField{
+			Name:        name,
+			Flag:        strings.ToLower(strings.Join(words, "-")),
+			Env:         configEnvPrefix + strings.ToUpper(strings.Join(words, "_")),
+			Format:      fieldFormat(configType.Field(i).Type),
+			Description: configFieldDescriptions[name],
+		})
+	}
+	return fields
+}
+
+// the overrides set in the environment, by field name
+func EnvOverrides(lookup func(string) (string, bool)) map[string]string {
+	overrides := map[string]string{}
+	for _, field := range OverridableConfigFields() {
+		if value, ok := lookup(field.Env); ok {
+			overrides[field.Name] = value
+		}
+	}
+	return overrides
+}
+
+/*
+Overlays the values on the config, by field name. Like with the profiles, maps are merged key by key.
+A list can be given comma separated, everything else but text as json.
+*/
+func ApplyOverrides(config *model.Config, overrides map[string]string, source ConfigSource) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	fields := map[string]ConfigField{}
+	for _, field := range OverridableConfigFields() {
+		fields[field.Name] = field
+	}
+
+	var problems ValidationErrors
+	overlay := map[string]json.RawMessage{}
+	for _, name := range sortedKeys(overrides) {
+		field, known := fields[name]
+		if !known {
+			problems = append(problems, ValidationError{
+				Field:   name,
+				Value:   overrides[name],
+				Problem: "unknown field in " + string(source) + " overrides",
+				Fix:     "override one of the fields of config.json",
+			})
+			continue
+		}
+		value, err := overrideJSON(field.Format, overrides[name])
+		if err != nil {
+			problems = append(problems, overrideProblem(field, overrides[name], source))
+			continue
+		}
+		overlay[name] = value
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		return err
+	}
+	return overlayConfig(config, data, string(source)+" overrides")
+}
+
+/*
+Loads config.json and layers the profile, the environment and the flags over it.
+The relative paths of the file and the profile are resolved against the folder of config.json,
+the ones of the environment and the flags against the working directory, like any other path given on the command line.
+Returns the effective config and the layer every field came from.
+*/
+func LoadConfig(path string, layers ConfigLayers) (*model.Config, map[string]ConfigSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening config file: %w", err)
+	}
+	config := &model.Config{}
+	if err := overlayConfig(config, data, "config file"); err != nil {
+		return nil, nil, err
+	}
+
+	sources := map[string]ConfigSource{}
+	var fileFields map[string]json.RawMessage
+	json.Unmarshal(data, &fileFields)
+	setSources(sources, fileFields, SourceFile)
+
+	if err := ApplyProfile(config, layers.Profile); err != nil {
+		return nil, nil, err
+	}
+	if layers.Profile != "" {
+		var profileFields map[string]json.RawMessage
+		json.Unmarshal(config.Profiles[layers.Profile], &profileFields)
+		setSources(sources, profileFields, SourceProfile)
+	}
+	if err := ResolveConfigPaths(config, configDirectory(path)); err != nil {
+		return nil, nil, err
+	}
+
+	if err := ApplyOverrides(config, layers.Env, SourceEnv); err != nil {
+		return nil, nil, err
+	}
+	setSources(sources, layers.Env, SourceEnv)
+	if err := ApplyOverrides(config, layers.Flags, SourceFlag); err != nil {
+		return nil, nil, err
+	}
+	setSources(sources, layers.Flags, SourceFlag)
+
+	// the paths of the file are absolute by now, only the relative ones of the overrides are left
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find the working directory: %w", err)
+	}
+	if err := ResolveConfigPaths(config, workingDir); err != nil {
+		return nil, nil, err
+	}
+
+	for _, field := range OverridableConfigFields() {
+		if _, ok := sources[field.Name]; !ok {
+			sources[field.Name] = SourceDefault
+		}
+	}
+	return config, sources, nil
+}
+
+// the effective value of the field, as it would be written in config.json
+func ConfigFieldValue(config *model.Config, name string) string {
+	configType := reflect.TypeOf(*config)
+	for i := 0; i < configType.NumField(); i++ {
+		if jsonFieldName(configType.Field(i)) != name {
+			continue
+		}
+		data, err := json.Marshal(reflect.ValueOf(*config).Field(i).Interface())
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+	return ""
+}
+
+func setSources[V any](sources map[string]ConfigSource, fields map[string]V, source ConfigSource) {
+	for name := range fields {
+		sources[name] = source
+	}
+}
+
+func overrideJSON(format string, value string) (json.RawMessage, error) {
+	switch format {
+	case FieldFormatString:
+		return json.Marshal(value)
+	case FieldFormatList:
+		if strings.HasPrefix(strings.TrimSpace(value), "[") {
+			return validJSON(value)
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return json.Marshal(items)
+	default:
+		return validJSON(value)
+	}
+}
+
+func validJSON(value string) (json.RawMessage, error) {
+	if !json.Valid([]byte(value)) {
+		return nil, errors.New("invalid json")
+	}
+	return json.RawMessage(value), nil
+}
+
+func overrideProblem(field ConfigField, value string, source ConfigSource) ValidationError {
+	setBy := field.Env
+	if source == SourceFlag {
+		setBy = "--" + field.Flag
+	}
+	fix := "give it as json, like in config.json"
+	if field.Format == FieldFormatList {
+		fix = "give it comma separated, like Win64,Linux, or as a json array"
+	}
+	return ValidationError{Field: field.Name, Value: value, Problem: "invalid value of " + setBy, Fix: fix}
+}
+
+func fieldFormat(t reflect.Type) string {
+	switch {
+	case t.Kind() == reflect.String:
+		return FieldFormatString
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		return FieldFormatList
+	default:
+		return FieldFormatJSON
+	}
+}
+
+// outputBaseDirectory becomes output, Base, Directory
+func splitCamelCase(name string) []string {
+	var words []string
+	start := 0
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	return append(words, name[start:])
+}
+
+// the folder the relative paths of the config are resolved against
+func configDirectory(configPath string) string {
+	dir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return filepath.Dir(configPath)
+	}
+	return dir
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestOverridableConfigFieldsShouldHaveAFlagAndAnEnvironmentVariable(t *testing.T) {
+	// when
+	fields := OverridableConfigFields()
+
+	// then
+	byName := map[string]ConfigField{}
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+	expected := ConfigField{
+		Name:        "outputBaseDirectory",
+		Flag:        "output-base-directory",
+		Env:         "UPR_OUTPUT_BASE_DIRECTORY",
+		Format:      FieldFormatString,
+		Description: configFieldDescriptions["outputBaseDirectory"],
+	}
+	if byName["outputBaseDirectory"] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, byName["outputBaseDirectory"])
+	}
+	if byName["targetPlatforms"].Format != FieldFormatList || byName["extraUatArgs"].Format != FieldFormatJSON {
+		t.Errorf("The format should follow the type of the field, got %+v", fields)
+	}
+	if _, ok := byName["profiles"]; ok {
+		t.Error("The profiles should not be overridable.")
+	}
+}
+
+// runs the test in the directory, as if the tool was started from it
+func changeWorkingDirectory(dir string, t *testing.T) {
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+}
+
+func TestLoadConfigShouldLayerFlagsOverEnvOverProfileOverFile(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	workingDir := t.TempDir()
+	changeWorkingDirectory(workingDir, t)
+	configPath := filepath.Join(dir, model.ConfigFile)
+	data := `{
+		"buildScriptPath": "RunUAT.bat",
+		"outputBaseDirectory": "File",
+		"docsPath": "File.pdf",
+		"pluginPath": "File.uplugin",
+		"engines": {"5.3": "D:/UE_5.3"},
+		"profiles": {"ci": {"outputBaseDirectory": "Profile", "docsPath": "Profile.pdf", "pluginPath": "Profile.uplugin"}}
+	}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	layers := ConfigLayers{
+		Profile: "ci",
+		Env: map[string]string{
+			"outputBaseDirectory": "Env",
+			"docsPath":            "Env.pdf",
+			"targetPlatforms":     "Win64, Linux",
+			"engines":             `{"5.4": "D:/UE_5.4"}`,
+		},
+		Flags: map[string]string{"outputBaseDirectory": "Flag"},
+	}
+
+	// when
+	config, sources, err := LoadConfig(configPath, layers)
+
+	// then
+	if err != nil {
+		t.Fatalf("The config should load: %v", err)
+	}
+	if config.OutputBaseDirectory != filepath.Join(workingDir, "Flag") || config.DocsPath != filepath.Join(workingDir, "Env.pdf") || config.PluginPath != filepath.Join(dir, "Profile.uplugin") || config.BuildScriptPath != "RunUAT.bat" {
+		t.Errorf("Every field should come from its highest layer, got %+v", config)
+	}
+	if !reflect.DeepEqual(config.TargetPlatforms, []string{"Win64", "Linux"}) || len(config.Engines) != 2 {
+		t.Errorf("Lists should be split and maps merged, got %v and %v", config.TargetPlatforms, config.Engines)
+	}
+	expected := map[string]ConfigSource{
+		"outputBaseDirectory": SourceFlag,
+		"docsPath":            SourceEnv,
+		"pluginPath":          SourceProfile,
+		"buildScriptPath":     SourceFile,
+		"hooks":               SourceDefault,
+	}
+	for field, source := range expected {
+		if sources[field] != source {
+			t.Errorf("%s should come from %s, got %s", field, source, sources[field])
+		}
+	}
+}
+
+func TestApplyOverridesShouldReportTheVariableOfAnInvalidValue(t *testing.T) {
+	// given
+	config := model.Config{}
+
+	// when
+	err := ApplyOverrides(&config, map[string]string{"retry": `{"maxAttempts": 3}`, "stages": "{"}, SourceEnv)
+
+	// then
+	var problems ValidationErrors
+	if !errors.As(err, &problems) || len(problems) != 1 || problems[0].Problem != "invalid value of UPR_STAGES" {
+		t.Errorf("The invalid json should be reported with its variable, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+// makes the paths of the config portable: ~, ${VAR}, forward slashes, paths relative to the config, and values per OS.
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+// the keys of a value given per OS, like {"windows": "D:/Epic Games", "linux": "~/UnrealEngine"}
+var platformKeys = []string{"windows", "linux", "darwin"}
+
+// only the braced form, as a bare $ is part of Windows share names like \\nas\D$
+var environmentVariableExpression = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+/*
+Expands ~ and ${VAR} in the paths of the config and turns forward slashes into the ones of the OS.
+Relative paths are resolved against baseDir, unless it's empty. buildScriptPath is relative to the engines, so it's only expanded.
+*/
+func ResolveConfigPaths(config *model.Config, baseDir string) error {
+	var problems ValidationErrors
+	resolve := func(field string, path *string, relativeTo string) {
+		resolved, problem := resolvePath(field, *path, relativeTo)
+		if problem != nil {
+			problems = append(problems, *problem)
+			return
+		}
+		*path = resolved
+	}
+
+	resolve("engineBaseDirectory", &config.EngineBaseDirectory, baseDir)
+	resolve("buildScriptPath", &config.BuildScriptPath, "")
+	resolve("outputBaseDirectory", &config.OutputBaseDirectory, baseDir)
+	resolve("pluginPath", &config.PluginPath, baseDir)
+	resolve("docsPath", &config.DocsPath, baseDir)
+	resolve("launcherInstalledPath", &config.LauncherInstalledPath, baseDir)
+	for _, version := range sortedKeys(config.Engines) {
+		location := config.Engines[version]
+		resolve("engines."+version, &location, baseDir)
+		config.Engines[version] = location
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+func resolvePath(field string, path string, baseDir string) (string, *ValidationError) {
+	if path == "" {
+		return "", nil
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", &ValidationError{Field: field, Value: path, Problem: "the home folder is unknown: " + err.Error(), Fix: "write the path without ~"}
+		}
+		path = home + path[1:]
+	}
+
+	var missing []string
+	path = environmentVariableExpression.ReplaceAllStringFunc(path, func(reference string) string {
+		name := environmentVariableExpression.FindStringSubmatch(reference)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", &ValidationError{
+			Field:   field,
+			Value:   path,
+			Problem: "environment variable " + strings.Join(missing, ", ") + " is not set",
+			Fix:     "set it, or write the path without it",
+		}
+	}
+
+	path = filepath.FromSlash(path)
+	if baseDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	return path, nil
+}
+
+/*
+Replaces the values given per OS in the data of the config with the one of the running OS.
+A field without a value for the running OS is left out, as if it wasn't set.
+Text fields, the engines and the hooks can be given per OS.
+*/
+func selectPlatformValues(data []byte, goos string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// the decoder reports what's wrong with it
+		return data, nil
+	}
+
+	var problems ValidationErrors
+	for _, name := range platformTextFields() {
+		problems = append(problems, selectPlatformValue(fields, name, name, goos)...)
+	}
+	for _, name := range []string{"engines", "hooks"} {
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(fields[name], &nested); err != nil || nested == nil {
+			continue
+		}
+		for _, key := range sortedKeys(nested) {
+			problems = append(problems, selectPlatformValue(nested, key, name+"."+key, goos)...)
+		}
+		fields[name], _ = json.Marshal(nested)
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return json.Marshal(fields)
+}
+
+func selectPlatformValue(fields map[string]json.RawMessage, key string, field string, goos string) ValidationErrors {
+	var values map[string]string
+	if err := json.Unmarshal(fields[key], &values); err != nil || values == nil {
+		// not given per OS
+		return nil
+	}
+
+	for _, platform := range sortedKeys(values) {
+		if !slices.Contains(platformKeys, platform) {
+			return ValidationErrors{{
+				Field:   field,
+				Value:   platform,
+				Problem: "unknown OS",
+				Fix:     "use " + strings.Join(platformKeys, ", ") + " as the keys of a value given per OS",
+			}}
+		}
+	}
+
+	if value, ok := values[goos]; ok {
+		fields[key], _ = json.Marshal(value)
+	} else {
+		delete(fields, key)
+	}
+	return nil
+}
+
+// the text fields of the config that can be given per OS
+func platformTextFields() []string {
+	var names []string
+	for _, field := range OverridableConfigFields() {
+		if field.Format == FieldFormatString {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
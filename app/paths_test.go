@@ -0,0 +1,109 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestResolveConfigPathsShouldExpandAndResolveRelativePaths(t *testing.T) {
+	// given
+	base := t.TempDir()
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("No home folder to expand ~ to.")
+	}
+	t.Setenv("UPR_TEST_WORKSPACE", base)
+	config := model.Config{
+		EngineBaseDirectory: "~/Epic Games",
+		BuildScriptPath:     "Engine/Build/BatchFiles/RunUAT.sh",
+		OutputBaseDirectory: "${UPR_TEST_WORKSPACE}/Releases",
+		PluginPath:          "../Plugins/MyPlugin/MyPlugin.uplugin",
+		Engines:             map[string]string{"5.4": "Engines/UE_5.4"},
+	}
+
+	// when
+	err = ResolveConfigPaths(&config, base)
+
+	// then
+	if err != nil {
+		t.Fatalf("The paths should resolve: %v", err)
+	}
+	expected := model.Config{
+		EngineBaseDirectory: filepath.Join(home, "Epic Games"),
+		BuildScriptPath:     filepath.Join("Engine", "Build", "BatchFiles", "RunUAT.sh"),
+		OutputBaseDirectory: filepath.Join(base, "Releases"),
+		PluginPath:          filepath.Join(filepath.Dir(base), "Plugins", "MyPlugin", "MyPlugin.uplugin"),
+		Engines:             map[string]string{"5.4": filepath.Join(base, "Engines", "UE_5.4")},
+	}
+	if config.EngineBaseDirectory != expected.EngineBaseDirectory || config.BuildScriptPath != expected.BuildScriptPath ||
+		config.OutputBaseDirectory != expected.OutputBaseDirectory || config.PluginPath != expected.PluginPath ||
+		config.Engines["5.4"] != expected.Engines["5.4"] || config.DocsPath != "" {
+		t.Errorf("Expected %+v, got %+v", expected, config)
+	}
+}
+
+func TestResolveConfigPathsShouldReportMissingEnvironmentVariables(t *testing.T) {
+	// given
+	config := model.Config{OutputBaseDirectory: "${UPR_TEST_MISSING_VARIABLE}/Releases"}
+
+	// when
+	err := ResolveConfigPaths(&config, t.TempDir())
+
+	// then
+	var problems ValidationErrors
+	if !errors.As(err, &problems) || problems[0].Field != "outputBaseDirectory" {
+		t.Errorf("The missing variable should be reported with its field, got %v", err)
+	}
+}
+
+func TestCreateConfigShouldSelectTheValuesOfTheRunningOS(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, model.ConfigFile)
+	data := `{
+		"engineBaseDirectory": {"windows": "Windows", "linux": "Linux", "darwin": "Darwin"},
+		"buildScriptPath": {"windows": "RunUAT.bat"},
+		"engines": {"5.4": {"windows": "UE_5.4", "linux": "UE_5.4", "darwin": "UE_5.4"}},
+		"hooks": {"postBatch": {"windows": "notify.bat", "linux": "notify.sh", "darwin": "notify.sh"}}
+	}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// when
+	config, err := CreateConfig(configPath)
+
+	// then
+	if err != nil {
+		t.Fatalf("The config should load: %v", err)
+	}
+	expected := map[string]string{"windows": "Windows", "linux": "Linux", "darwin": "Darwin"}[runtime.GOOS]
+	if config.EngineBaseDirectory != filepath.Join(dir, expected) || config.Engines["5.4"] != filepath.Join(dir, "UE_5.4") || config.Hooks == nil {
+		t.Errorf("The values of %s should be used, got %+v", runtime.GOOS, config)
+	}
+	if runtime.GOOS != "windows" && config.BuildScriptPath != "" {
+		t.Errorf("A field without a value for the OS should be left out, got %q", config.BuildScriptPath)
+	}
+}
+
+func TestCreateConfigShouldRejectUnknownOS(t *testing.T) {
+	// given
+	configPath := filepath.Join(t.TempDir(), model.ConfigFile)
+	if err := os.WriteFile(configPath, []byte(`{"pluginPath": {"win64": "MyPlugin.uplugin"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// when
+	_, err := CreateConfig(configPath)
+
+	// then
+	var problems ValidationErrors
+	if !errors.As(err, &problems) || problems[0].Field != "pluginPath" || problems[0].Value != "win64" {
+		t.Errorf("The unknown OS should be reported, got %v", err)
+	}
+}
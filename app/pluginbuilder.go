@@ -2,9 +2,16 @@
 package app
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"unreal-plugin-release/executor"
 	"unreal-plugin-release/model"
@@ -12,98 +19,489 @@ import (
 
 // the application that encapsulates the core business logic with a configuration as input
 type PluginBuilder struct {
-	config *model.Config
-	runner executor.SubprocessExecutor
+	config  *model.Config
+	runner  executor.SubprocessExecutor
+	fs      FileSystem
+	log     *slog.Logger
+	onEvent func(model.Event)
+	guard   *DeletionGuard
+	engines *EngineResolver
+	// the built-in stages and the ones of the options, by name
+	stageFactories map[string]StageFactory
+	report         model.BuildReport
+	// the progress of the running batch, written as it goes
+	journal *model.BatchJournal
+	// whether the plugin has no modules, so a single build serves every version
+	contentOnly bool
+	// the first build of a content-only plugin, reused for the rest of the versions
+	contentOnlyBuild string
+	// the version the content-only build was made for, and whether it's the copied source instead of a build
+	contentOnlyBuiltVersion string
+	contentOnlyPackageOnly  bool
+	sleep                   func(context.Context, time.Duration) error
+	now                     func() time.Time
+}
+
+// what a program embedding the plugin builder can replace, anything left empty falls back to the default
+type BuilderOptions struct {
+	// the OS file system by default
+	FileSystem FileSystem
+	// slog's default logger by default
+	Logger *slog.Logger
+	// called with every event of the batch, in order, on the goroutine running it
+	OnEvent func(model.Event)
+	// stages the config may list besides the built-in ones, by name, replacing a built-in stage of the same name
+	Stages map[string]StageFactory
 }
 
 /*
 Constructor for the plugin builder.
 */
 func NewPluginBuilder(config *model.Config, runner executor.SubprocessExecutor) *PluginBuilder {
-	return &PluginBuilder{config, runner}
+	return NewPluginBuilderWithOptions(config, runner, BuilderOptions{})
+}
+
+/*
+Constructor for the plugin builder with its file system, logger, event listener or stages replaced.
+*/
+func NewPluginBuilderWithOptions(config *model.Config, runner executor.SubprocessExecutor, options BuilderOptions) *PluginBuilder {
+	pb := &PluginBuilder{
+		config:  config,
+		runner:  runner,
+		fs:      options.FileSystem,
+		log:     options.Logger,
+		onEvent: options.OnEvent,
+		sleep:   sleepContext,
+		now:     time.Now,
+	}
+	if pb.fs == nil {
+		pb.fs = OSFileSystem{}
+	}
+	pb.stageFactories = maps.Clone(builtinStages)
+	maps.Copy(pb.stageFactories, options.Stages)
+	pb.guard = newDeletionGuard(config, pb.fs)
+	pb.engines = NewEngineResolverWithFileSystem(config, pb.fs)
+	if pb.log == nil {
+		pb.log = defaultLogger()
+	}
+	pb.guard.log = pb.log
+	pb.engines.log = pb.log
+	return pb
 }
 
 /*
-Builds the plugins for all selected versions.
+Builds the plugins for all selected versions, running every version through the stages of the release pipeline.
+The first failure stops the batch, and is returned after its staging area is cleaned up.
+A version whose preVersion hook fails is skipped instead, the batch goes on with the next one and fails at its end.
+The output directory is locked for the whole batch, a LockedError is returned if another run holds it.
+The progress is written to a journal as the versions and stages complete, so with Resume, an interrupted batch continues
+at the first incomplete version and stage, as long as its inputs are unchanged.
 */
-func (pb *PluginBuilder) BuildPluginsForSelectedVersions(cmdInput model.CmdInput, execPath string) {
+func (pb *PluginBuilder) BuildPluginsForSelectedVersions(ctx context.Context, cmdInput model.CmdInput, execPath string) error {
+	stages, err := pb.createStages()
+	if err != nil {
+		return fmt.Errorf("invalid stages: %w", err)
+	}
+
+	unlock, err := pb.lockOutputDirectory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	versions := pb.collectVersions(cmdInput.EngineVersions)
-	pluginName := createPluginName(pb.config.PluginPath)
+	pb.printBuildPlan(versions, stages, cmdInput)
+
+	pb.contentOnly = len(versions) > 1 && isContentOnlyPlugin(pb.fs, pb.config.PluginPath)
+	if pb.contentOnly {
+		pb.log.Info("content-only plugin without modules, building once and reusing the result for every version", "path", pb.config.PluginPath)
+	}
 
+	if err := pb.startJournal(cmdInput); err != nil {
+		return err
+	}
+
+	if err := pb.runHook(ctx, preBatchHook, nil, hookStatusRunning); err != nil {
+		return pb.abortBatch(ctx, nil, fmt.Errorf("batch aborted: %w", err))
+	}
+
+	var skipped []error
 	for _, version := range versions {
 		version = strings.TrimSpace(version)
 		if version == "" {
 			continue
 		}
 
-		outputDir := combineOutputDir(pluginName, version, pb.config.OutputBaseDirectory)
-		pb.runBuildForEngineVersion(version, outputDir, pb.config.PluginPath)
-		pb.postProcessRelease(outputDir, execPath, pb.config.DocsPath, cmdInput)
+		release, err := pb.newRelease(version, cmdInput, execPath)
+		if err != nil {
+			return pb.failVersion(ctx, &Release{Version: version}, err)
+		}
+
+		pb.emit(model.VersionStarted{Version: version, OutputDir: release.OutputDir})
+		if pb.journalVersion(version).Finished {
+			pb.log.Info("already released by the interrupted batch, skipped", "version", version, "path", release.OutputDir)
+			pb.emit(model.VersionFinished{Version: version, OutputDir: release.OutputDir})
+			continue
+		}
+		if err := pb.runHook(ctx, preVersionHook, release, hookStatusRunning); err != nil {
+			skipped = append(skipped, pb.skipVersion(ctx, release, err))
+			continue
+		}
+
+		started := pb.now()
+		if err := pb.runStages(ctx, pb.remainingStages(stages, release), release); err != nil {
+			return pb.failVersion(ctx, release, err)
+		}
+		duration := pb.now().Sub(started)
+		pb.recordVersionDuration(version, duration)
+		pb.saveVersionDuration(version, duration)
+		pb.recordVersionFinished(version)
+		pb.emit(model.VersionFinished{Version: version, OutputDir: release.OutputDir})
+	}
+
+	pb.discardContentOnlyBuild()
+	removeEmptyDirectory(pb.guard, pb.makeStagingRoot())
+	pb.saveReport()
+	if len(skipped) > 0 {
+		// the journal stays, so --resume goes back to the skipped versions
+		pb.runPostHook(ctx, postBatchHook, nil, hookStatusFailed)
+		return errors.Join(skipped...)
 	}
+	pb.finishJournal()
+	pb.applyRetentionAfterBatch()
+	pb.runPostHook(ctx, postBatchHook, nil, hookStatusSucceeded)
+	return nil
 }
 
-func (pb *PluginBuilder) postProcessRelease(outputDir, execPath, docsPath string, cmdInput model.CmdInput) {
-	pb.removeUnneededFolders(outputDir)
+/*
+What happened to every version of the last batch, the same as the build report.
+*/
+func (pb *PluginBuilder) Report() model.BuildReport {
+	return pb.report
+}
 
-	if docsPath != "" && !cmdInput.SkipDocs {
-		if err := pb.handleDocumentation(outputDir, execPath, docsPath); err != nil {
-			return
-		}
+func (pb *PluginBuilder) emit(event model.Event) {
+	if pb.onEvent != nil {
+		pb.onEvent(event)
 	}
+}
+
+/*
+The release of the version, whatever the mode. The engine is verified to be the requested version first,
+so its exact version is the one named and stamped, even without building.
+*/
+func (pb *PluginBuilder) newRelease(version string, cmdInput model.CmdInput, execPath string) (*Release, error) {
+	buildVersion, err := pb.verifyEngineIdentity(version)
+	if err != nil {
+		return nil, err
+	}
+	if buildVersion == nil {
+		pb.log.Warn("no Build.version found, cannot verify the engine version", "version", version, "path", pb.engines.Resolve(version).Root)
+	}
+
+	outputName, err := pb.outputNameFor(version, buildVersion, cmdInput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Release{
+		Version:     version,
+		StagingDir:  filepath.Join(pb.makeStagingRoot(), outputName),
+		OutputDir:   filepath.Join(pb.config.OutputBaseDirectory, outputName),
+		Platforms:   pb.targetPlatformsFor(version, cmdInput),
+		CmdInput:    cmdInput,
+		ExecPath:    execPath,
+		EngineBuild: buildVersion,
+	}, nil
+}
+
+// builds the release into the staging directory, or copies the source, or the earlier build of a content-only plugin
+func (pb *PluginBuilder) buildRelease(ctx context.Context, release *Release) error {
+	var err error
+	switch {
+	case pb.contentOnlyBuild != "":
+		err = pb.reuseContentOnlyBuild(release.Version, release.StagingDir, release.Platforms)
+	case release.CmdInput.PackageOnly:
+		err = pb.packageSourceForEngineVersion(ctx, release.Version, release.StagingDir, release.Platforms, release.CmdInput.VerifyBuild)
+	default:
+		err = pb.runBuildForEngineVersion(ctx, release.Version, release.StagingDir, pb.config.PluginPath, release.Platforms)
+	}
+
+	pb.recordEngineBuild(release)
+
+	if err == nil && pb.contentOnly && pb.contentOnlyBuild == "" {
+		err = pb.keepContentOnlyBuild(release)
+	}
+	return err
+}
+
+func (pb *PluginBuilder) stampRelease(ctx context.Context, release *Release) error {
+	return pb.stampDescriptor(release.StagingDir, exactEngineVersion(release.Version, release.EngineBuild), release.Platforms)
+}
+
+func (pb *PluginBuilder) documentRelease(ctx context.Context, release *Release) error {
+	if pb.config.DocsPath == "" || release.CmdInput.SkipDocs {
+		return nil
+	}
+	return pb.handleDocumentation(release.StagingDir, release.ExecPath, pb.config.DocsPath)
+}
+
+func (pb *PluginBuilder) archiveRelease(ctx context.Context, release *Release) error {
+	zipCmd := pb.runner.CreateZipCommand(release.StagingDir)
+	flush := forwardOutput(zipCmd, pb.log, "zip", nil, "version", release.Version)
+	defer flush()
 
-	if err := pb.runner.CreateZipCommand(outputDir).Run(); err != nil {
-		fmt.Println("⚠️ Failed to zip using PowerShell:", err)
+	if err := runCommand(ctx, zipCmd); err != nil {
+		return fmt.Errorf("failed to zip %s: %w", release.StagingDir, err)
+	}
+	return nil
+}
+
+func (pb *PluginBuilder) checksumRelease(ctx context.Context, release *Release) error {
+	archive := release.StagingDir + ".zip"
+	if !isFileIn(pb.fs, archive) {
+		return errors.New("no archive to checksum, the " + archiveStageName + " stage must come before it")
+	}
+	if err := writeChecksumFile(pb.fs, archive); err != nil {
+		return fmt.Errorf("failed to write archive checksum: %w", err)
 	}
+	return nil
+}
+
+func (pb *PluginBuilder) publishStagedRelease(ctx context.Context, release *Release) error {
+	if err := publishRelease(pb.guard, release.StagingDir, release.OutputDir); err != nil {
+		return err
+	}
+	pb.markVersionPublished(release.OutputDir)
+
+	pb.emit(model.ArtifactWritten{Version: release.Version, Kind: model.ArtifactRelease, Path: release.OutputDir})
+	artifactKinds := []model.ArtifactKind{model.ArtifactArchive, model.ArtifactChecksum}
+	for i, suffix := range releaseFileSuffixes {
+		if pathExists(pb.fs, release.OutputDir+suffix) {
+			pb.emit(model.ArtifactWritten{Version: release.Version, Kind: artifactKinds[i], Path: release.OutputDir + suffix})
+		}
+	}
+	return nil
 }
 
 func (pb *PluginBuilder) handleDocumentation(releaseDir, execPath, docsPath string) error {
 	sourceIni := GetFullPathForFileInExecDir(execPath, model.PluginConfigurationIniFileName)
-	if err := createConfigFolderWithIni(releaseDir, sourceIni); err != nil {
+	if err := createConfigFolderWithIni(pb.fs, releaseDir, sourceIni); err != nil {
 		return err
 	}
 
-	if err := copyPdfIntoDocsFolderAndRename(releaseDir, docsPath, GetFullPathForFileInExecDir(execPath, model.PluginConfigurationIniFileName)); err != nil {
+	if err := copyPdfIntoDocsFolderAndRename(pb.fs, releaseDir, docsPath, GetFullPathForFileInExecDir(execPath, model.PluginConfigurationIniFileName)); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (pb *PluginBuilder) removeUnneededFolders(releaseDir string) {
-	for _, folder := range pb.getUnneededFolders() {
-		deleteSubFolder(releaseDir, folder)
+func (pb *PluginBuilder) getUnneededFolders() []string {
+	return []string{"Binaries", "Build", "Intermediate", "Saved"}
+}
+
+func (pb *PluginBuilder) runBuildForEngineVersion(ctx context.Context, version, stagingDir, pluginPath string, platforms []string) error {
+	buildScriptPath, err := pb.makeBuildScriptFilePath(version)
+	if err != nil {
+		return err
+	}
+
+	pb.log.Info("building", "version", version, "platforms", platforms, "path", stagingDir)
+
+	if err := createOwnedDirectory(pb.fs, filepath.Dir(stagingDir)); err != nil {
+		return fmt.Errorf("failed to create staging folder: %w", err)
+	}
+
+	args, err := pb.uatArgumentsFor(version, platforms)
+	if err != nil {
+		return err
 	}
+
+	versionReport, err := pb.buildWithRetries(ctx, version, buildScriptPath, stagingDir, pluginPath, args)
+	versionReport.TargetPlatforms = platforms
+	pb.report.Versions = append(pb.report.Versions, versionReport)
+	return err
 }
 
-func (pb *PluginBuilder) getUnneededFolders() []string {
-	return []string{"Binaries", "Build", "Intermediate", "Saved"}
+// lists what is going to be built for every version, before anything is built
+func (pb *PluginBuilder) printBuildPlan(versions []string, stages []Stage, cmdInput model.CmdInput) {
+	pb.log.Info("build plan", "stages", strings.Join(stageNames(stages), " -> "))
+	for _, version := range versions {
+		version = strings.TrimSpace(version)
+		if version == "" {
+			continue
+		}
+
+		planned := pb.log.With("version", version)
+		buildVersion, err := pb.verifyEngineIdentity(version)
+		if err != nil {
+			planned.Warn("engine not verified", "error", err)
+		}
+		if outputName, err := pb.outputNameFor(version, buildVersion, cmdInput); err != nil {
+			planned.Warn("invalid output name", "error", err)
+		} else {
+			planned = planned.With("path", filepath.Join(pb.config.OutputBaseDirectory, outputName))
+		}
+		if cmdInput.PackageOnly && !cmdInput.VerifyBuild {
+			planned.Info("planned", "build", "source only")
+			continue
+		}
+
+		planned = planned.With("script", pb.engines.Resolve(version).BuildScriptPath)
+		if args, err := pb.uatArgumentsFor(version, pb.targetPlatformsFor(version, cmdInput)); err != nil {
+			planned.Warn("planned with invalid arguments", "error", err)
+		} else {
+			planned.Info("planned", "args", strings.Join(args, " "))
+		}
+	}
 }
 
-func (pb *PluginBuilder) runBuildForEngineVersion(version, outputDir, pluginPath string) {
-	buildScriptPath := pb.makeBuildScriptFilePath(version)
+// a failed version only takes its own staging area with it, releases that are already published stay untouched.
+// The release only needs the paths that exist by the time it failed.
+func (pb *PluginBuilder) failVersion(ctx context.Context, release *Release, err error) error {
+	pb.emit(model.VersionFinished{Version: release.Version, OutputDir: release.OutputDir, Err: err})
+	return pb.abortBatch(ctx, release, fmt.Errorf("build failed for %s: %w", release.Version, err))
+}
 
-	fmt.Println("======================================")
-	fmt.Println("Building for UE version", version)
-	fmt.Println("Output to:", outputDir)
-	fmt.Println("======================================")
+// a version whose preVersion hook failed is recorded as failed and left out, without stopping the rest of the batch
+func (pb *PluginBuilder) skipVersion(ctx context.Context, release *Release, err error) error {
+	err = fmt.Errorf("skipped %s: %w", release.Version, err)
+	pb.log.Error("version skipped", "version", release.Version, "error", err)
+	pb.report.Versions = append(pb.report.Versions, model.VersionReport{Version: release.Version, OutputDir: release.OutputDir})
+	pb.emit(model.VersionFinished{Version: release.Version, OutputDir: release.OutputDir, Err: err})
 
-	err := pb.runner.CreateBuilderCommand(buildScriptPath, pluginPath, outputDir).Run()
+	skipped := &Release{Version: release.Version, OutputDir: release.OutputDir}
+	if hookErr := pb.runHook(ctx, onFailureHook, skipped, hookStatusFailed, failureEnvironment(err)); hookErr != nil {
+		pb.log.Warn("hook failed", "hook", onFailureHook, "error", hookErr)
+	}
+	return err
+}
+
+// saves what happened so far, lets the onFailure hook know, and cleans up the staging area of the failed release
+func (pb *PluginBuilder) abortBatch(ctx context.Context, release *Release, err error) error {
+	pb.saveReport()
+	// the hook runs even if the batch was cancelled, it's the last chance to react to it
+	if hookErr := pb.runHook(context.WithoutCancel(ctx), onFailureHook, release, hookStatusFailed, failureEnvironment(err)); hookErr != nil {
+		pb.log.Warn("hook failed", "hook", onFailureHook, "error", hookErr)
+	}
+
+	pb.discardContentOnlyBuild()
+	if release != nil && release.StagingDir != "" {
+		discardStagedRelease(pb.guard, release.StagingDir)
+		removeEmptyDirectory(pb.guard, filepath.Dir(release.StagingDir))
+	}
+	return err
+}
+
+// once published, the report points to the final location instead of the staging area
+func (pb *PluginBuilder) markVersionPublished(outputDir string) {
+	if len(pb.report.Versions) > 0 {
+		pb.report.Versions[len(pb.report.Versions)-1].OutputDir = outputDir
+	}
+}
+
+// the exact engine version in the report, whether the release was built, packaged or reused
+func (pb *PluginBuilder) recordEngineBuild(release *Release) {
+	if release.EngineBuild == nil {
+		return
+	}
+	for i := len(pb.report.Versions) - 1; i >= 0; i-- {
+		if pb.report.Versions[i].Version == release.Version {
+			pb.report.Versions[i].EngineVersion = formatEngineBuildVersion(release.EngineBuild)
+			pb.report.Versions[i].Changelist = release.EngineBuild.Changelist
+			return
+		}
+	}
+}
+
+// kept in the report, so the next batch can estimate how long a version takes
+func (pb *PluginBuilder) recordVersionDuration(version string, duration time.Duration) {
+	for i := len(pb.report.Versions) - 1; i >= 0; i-- {
+		if pb.report.Versions[i].Version == version {
+			pb.report.Versions[i].DurationSeconds = duration.Round(time.Second).Seconds()
+			return
+		}
+	}
+}
+
+/*
+Merges the duration of the version into the ones of the earlier batches, which the report can't keep:
+a batch over a few versions, or a postprocess, replaces the report without the durations of the others.
+*/
+func (pb *PluginBuilder) saveVersionDuration(version string, duration time.Duration) {
+	path := filepath.Join(pb.config.OutputBaseDirectory, model.BuildDurationsFile)
+	durations, err := readBuildDurations(pb.fs, path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		pb.log.Warn("failed to read the durations of the earlier batches, starting over", "path", path, "error", err)
+		durations = map[string]float64{}
+	}
+	durations[version] = duration.Round(time.Second).Seconds()
+
+	data, err := json.MarshalIndent(durations, "", "  ")
+	if err == nil {
+		err = pb.fs.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		pb.log.Warn("failed to write the build durations", "path", path, "error", err)
+		return
+	}
+	markOwned(pb.fs, pb.config.OutputBaseDirectory, model.BuildDurationsFile)
+}
+
+func (pb *PluginBuilder) makeStagingRoot() string {
+	return filepath.Join(pb.config.OutputBaseDirectory, model.StagingDirectoryName)
+}
+
+func (pb *PluginBuilder) saveReport() {
+	reportPath := filepath.Join(pb.config.OutputBaseDirectory, model.BuildReportFile)
+	if err := writeBuildReport(pb.fs, reportPath, pb.report); err != nil {
+		pb.log.Warn("failed to write build report", "path", reportPath, "error", err)
+		return
+	}
+	markOwned(pb.fs, pb.config.OutputBaseDirectory, model.BuildReportFile)
+	pb.emit(model.ArtifactWritten{Kind: model.ArtifactReport, Path: reportPath})
+}
+
+// the --platforms flag wins over the version's own platforms, which win over the global ones
+func (pb *PluginBuilder) targetPlatformsFor(version string, cmdInput model.CmdInput) []string {
+	if cmdInput.Platforms != "" {
+		return collectPlatforms(cmdInput.Platforms)
+	}
+	if versionConfig, ok := pb.config.Versions[version]; ok && len(versionConfig.TargetPlatforms) > 0 {
+		return versionConfig.TargetPlatforms
+	}
+	return pb.config.TargetPlatforms
+}
+
+// writes the exact engine version into the packaged descriptor, and the selected platforms if there are any
+func (pb *PluginBuilder) stampDescriptor(releaseDir string, engineVersion string, platforms []string) error {
+	descriptorPath := filepath.Join(releaseDir, filepath.Base(pb.config.PluginPath))
+	descriptor, err := readPluginDescriptor(pb.fs, descriptorPath)
 	if err != nil {
-		fmt.Println("Build failed for", version, ":", err)
-		removeDirectory(pb.config.OutputBaseDirectory)
-		os.Exit(1)
+		return err
+	}
+
+	if err := descriptor.setValue("EngineVersion", engineVersion); err != nil {
+		return err
+	}
+
+	if len(platforms) > 0 {
+		if err := descriptor.setValue("SupportedTargetPlatforms", platforms); err != nil {
+			return err
+		}
 	}
+	return descriptor.write(pb.fs, descriptorPath)
 }
 
 func (pb *PluginBuilder) collectVersions(input string) []string {
 	return strings.Split(input, ",")
 }
 
-func (pb *PluginBuilder) makeBuildScriptFilePath(version string) string {
-	batPath := createBatFilePath(pb.config.EngineBaseDirectory, version, pb.config.BuildScriptPath)
-	if !isFilePathValid(batPath) {
-		fmt.Println("Build script not found for engine version", version, ":", batPath)
-		os.Exit(1)
+func (pb *PluginBuilder) makeBuildScriptFilePath(version string) (string, error) {
+	batPath := pb.engines.Resolve(version).BuildScriptPath
+	if !isFilePathValid(pb.fs, batPath) {
+		return "", fmt.Errorf("build script not found for engine version %s: %s", version, batPath)
 	}
-	return batPath
+	return batPath, nil
 }
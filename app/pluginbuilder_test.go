@@ -4,12 +4,15 @@ Running the actual app would require some form of implementation
 package app
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"unreal-plugin-release/model"
 )
@@ -18,7 +21,7 @@ import (
 type FakeExecutor struct {
 }
 
-func (e FakeExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string) *exec.Cmd {
+func (e FakeExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string, extraArgs []string) *exec.Cmd {
 	const message = "Failed to write directory"
 	// create a bunch of directories as if the plugin has been built.
 	if err := os.MkdirAll(filepath.Join(outputDir, "Source"), 0755); err != nil {
@@ -45,6 +48,13 @@ func (e FakeExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocatio
 		panic(message)
 	}
 
+	// UAT packages the descriptor next to the built folders
+	if IsFile(pluginLocation) {
+		if err := copyFile(OSFileSystem{}, pluginLocation, filepath.Join(outputDir, filepath.Base(pluginLocation))); err != nil {
+			panic(message)
+		}
+	}
+
 	return createEmptyCommand()
 }
 
@@ -60,6 +70,17 @@ func (e FakeExecutor) CreateZipCommand(sourceDir string) *exec.Cmd {
 	return createEmptyCommand()
 }
 
+func (e FakeExecutor) CreateGitCommitCommand(repositoryDir string) *exec.Cmd {
+	return createEmptyCommand()
+}
+
+func (e FakeExecutor) CreateHookCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
 func createEmptyCommand() *exec.Cmd {
 	if runtime.GOOS == "windows" {
 		return exec.Command("cmd", "/c", "rem")
@@ -116,9 +137,12 @@ func TestMakeBuildScriptFilePath(t *testing.T) {
 	expected := filepath.Join(engine, "UE_"+version, buildScriptRelativePath)
 
 	// when
-	actual := underTest.makeBuildScriptFilePath(version)
+	actual, err := underTest.makeBuildScriptFilePath(version)
 
 	// then
+	if err != nil {
+		t.Fatalf("The build script should have been found: %v", err)
+	}
 	if actual != expected {
 		t.Errorf("Expected: %q, Actual: %q", expected, actual)
 	}
@@ -131,7 +155,7 @@ func TestBuildPlugin(t *testing.T) {
 	buildScriptRelativePath := "RunUAT.bat"
 	base := t.TempDir()
 	engine := makeDir(base, "Engine", t)
-	uplugin := makeFile(base, "MyPlugin.uplugin", t)
+	uplugin := writeDescriptor(base, testDescriptor, t)
 	writeBuildScript(engine, version, buildScriptRelativePath, t)
 	docs := filepath.Join(fixtures, "testing.pdf")
 	writeFilterPluginFile(base, t)
@@ -156,7 +180,9 @@ func TestBuildPlugin(t *testing.T) {
 	underTest := NewPluginBuilder(&config, executor)
 
 	// when
-	underTest.BuildPluginsForSelectedVersions(cmdInput, execPath)
+	if err := underTest.BuildPluginsForSelectedVersions(context.Background(), cmdInput, execPath); err != nil {
+		t.Fatalf("The batch should have succeeded: %v", err)
+	}
 
 	// then
 	if isDirectoryExist(filepath.Join(builtPluginPath, "Binaries")) {
@@ -190,6 +216,191 @@ func TestBuildPlugin(t *testing.T) {
 	if !isFileExist(filepath.Join(builtPluginPath, "Config", "FilterPlugin.ini")) {
 		t.Error("FilterPlugin file is not in the Config folder.")
 	}
+
+	descriptor, err := readPluginDescriptor(OSFileSystem{}, filepath.Join(builtPluginPath, "MyPlugin.uplugin"))
+	var engineVersion string
+	if err == nil {
+		descriptor.getValue("EngineVersion", &engineVersion)
+	}
+	if engineVersion != "5.4.0" {
+		t.Errorf("The packaged descriptor should be stamped with the engine version, got %q", engineVersion)
+	}
+
+	if !isFileExist(builtPluginPath + ".zip") {
+		t.Error("The archive was not published to the output directory.")
+	}
+
+	if IsPathExist(filepath.Join(output, ".staging")) {
+		t.Error("The staging area should be gone after a successful build.")
+	}
+}
+
+func TestPackageOnlyShouldCopySourceWithoutBuilding(t *testing.T) {
+	// given
+	base := t.TempDir()
+	uplugin := writeDescriptor(base, testDescriptor, t)
+	makeFile(filepath.Join(base, "Source"), "MyActor.cpp", t)
+	makeFile(filepath.Join(base, "Intermediate"), "Build.tmp", t)
+	writeFilterPluginFile(base, t)
+	output := makeDir(base, "Output", t)
+	builtPluginPath := filepath.Join(output, "MyPlugin_5.4")
+
+	config := model.Config{
+		EngineBaseDirectory: filepath.Join(base, "Engine"),
+		BuildScriptPath:     "RunUAT.bat",
+		OutputBaseDirectory: output,
+		PluginPath:          uplugin,
+	}
+	failures := 1
+	underTest := NewPluginBuilder(&config, FailingExecutor{failures: &failures, message: "UAT must not run"})
+
+	// when
+	if err := underTest.BuildPluginsForSelectedVersions(context.Background(), model.CmdInput{EngineVersions: "5.4", PackageOnly: true}, filepath.Join(base, "script.exe")); err != nil {
+		t.Fatalf("The batch should have succeeded: %v", err)
+	}
+
+	// then
+	if failures != 1 {
+		t.Error("The plugin should not have been built.")
+	}
+	if !isFileExist(filepath.Join(builtPluginPath, "Source", "MyActor.cpp")) {
+		t.Error("Source missing from release.")
+	}
+	if IsPathExist(filepath.Join(builtPluginPath, "Intermediate")) {
+		t.Error("Intermediate should have been left out of the release.")
+	}
+	if IsPathExist(filepath.Join(builtPluginPath, "Output")) {
+		t.Error("The output should not have been copied into the release.")
+	}
+
+	descriptor, err := readPluginDescriptor(OSFileSystem{}, filepath.Join(builtPluginPath, "MyPlugin.uplugin"))
+	var engineVersion string
+	if err == nil {
+		descriptor.getValue("EngineVersion", &engineVersion)
+	}
+	if engineVersion != "5.4.0" {
+		t.Errorf("The packaged descriptor should be stamped with the engine version, got %q", engineVersion)
+	}
+	if !isFileExist(builtPluginPath + ".zip") {
+		t.Error("The archive was not published to the output directory.")
+	}
+	if len(underTest.report.Versions) != 1 || !underTest.report.Versions[0].PackageOnly {
+		t.Errorf("The report should record the package-only release, got %+v", underTest.report.Versions)
+	}
+}
+
+func TestPackageOnlyShouldRefuseAnEngineOfAnotherVersion(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := writeTestProject(base, nil, t)
+	writeBuildVersion(filepath.Join(config.EngineBaseDirectory, "UE_5.4"), testBuildVersion, t)
+	underTest := NewPluginBuilder(config, FakeExecutor{})
+
+	// when
+	err := underTest.BuildPluginsForSelectedVersions(context.Background(), model.CmdInput{EngineVersions: "5.4", PackageOnly: true, SkipDocs: true}, filepath.Join(base, "script.exe"))
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "is UE 5.3.2, not 5.4") {
+		t.Errorf("The 5.3 engine in the UE_5.4 folder should be refused, got %v", err)
+	}
+	if IsPathExist(filepath.Join(config.OutputBaseDirectory, "MyPlugin_5.4")) {
+		t.Error("Nothing should be released for the mismatched engine.")
+	}
+}
+
+func TestTargetPlatformsForShouldPreferFlagThenVersion(t *testing.T) {
+	// given
+	config := model.Config{
+		TargetPlatforms: []string{"Win64"},
+		Versions: map[string]model.VersionConfig{
+			"5.4": {TargetPlatforms: []string{"Win64", "Android"}},
+		},
+	}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	fromFlag := underTest.targetPlatformsFor("5.4", model.CmdInput{Platforms: "Linux,Mac"})
+	fromVersion := underTest.targetPlatformsFor("5.4", model.CmdInput{})
+	fromConfig := underTest.targetPlatformsFor("5.3", model.CmdInput{})
+
+	// then
+	if !arrayContainsAll([]string{"Linux", "Mac"}, fromFlag) {
+		t.Errorf("The flag should win, got %v", fromFlag)
+	}
+	if !arrayContainsAll([]string{"Win64", "Android"}, fromVersion) {
+		t.Errorf("The version's platforms should win over the global ones, got %v", fromVersion)
+	}
+	if !arrayContainsAll([]string{"Win64"}, fromConfig) {
+		t.Errorf("The global platforms should be used, got %v", fromConfig)
+	}
+}
+
+func TestRecordVersionDurationShouldUpdateTheLatestReportOfTheVersion(t *testing.T) {
+	// given
+	underTest := NewPluginBuilder(&model.Config{}, FakeExecutor{})
+	underTest.report.Versions = []model.VersionReport{{Version: "5.3"}, {Version: "5.4"}}
+
+	// when
+	underTest.recordVersionDuration("5.3", 95*time.Second+400*time.Millisecond)
+
+	// then
+	if underTest.report.Versions[0].DurationSeconds != 95 || underTest.report.Versions[1].DurationSeconds != 0 {
+		t.Errorf("Only 5.3 should have its duration in whole seconds, got %+v", underTest.report.Versions)
+	}
+}
+
+func TestBuildDurationsShouldBeKeptAcrossBatches(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := writeTestProject(base, []string{"5.3", "5.4"}, t)
+	clock := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	tick := func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	first := NewPluginBuilder(config, FakeExecutor{})
+	first.now = tick
+	if err := first.BuildPluginsForSelectedVersions(context.Background(), model.CmdInput{EngineVersions: "5.3,5.4", SkipDocs: true}, filepath.Join(base, "script.exe")); err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	second := NewPluginBuilder(config, FakeExecutor{})
+	second.now = tick
+	err := second.BuildPluginsForSelectedVersions(context.Background(), model.CmdInput{EngineVersions: "5.4", SkipDocs: true}, filepath.Join(base, "script.exe"))
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+	durations, err := ReadBuildDurations(filepath.Join(config.OutputBaseDirectory, model.BuildDurationsFile))
+	if err != nil || durations["5.3"] <= 0 || durations["5.4"] <= 0 {
+		t.Errorf("The durations of both versions should be kept, got %v (%v)", durations, err)
+	}
+}
+
+func TestStampDescriptorShouldWriteSupportedTargetPlatforms(t *testing.T) {
+	// given
+	releaseDir := t.TempDir()
+	descriptorPath := writeDescriptor(releaseDir, testDescriptor, t)
+	config := model.Config{PluginPath: filepath.Join(t.TempDir(), "MyPlugin.uplugin")}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	err := underTest.stampDescriptor(releaseDir, "5.3.2", []string{"Win64", "Linux"})
+
+	// then
+	descriptor, _ := readPluginDescriptor(OSFileSystem{}, descriptorPath)
+	var platforms []string
+	var engineVersion string
+	descriptor.getValue("SupportedTargetPlatforms", &platforms)
+	descriptor.getValue("EngineVersion", &engineVersion)
+	if err != nil || !arrayContainsAll([]string{"Win64", "Linux"}, platforms) {
+		t.Errorf("Expected the selected platforms in the descriptor, got %v (%v)", platforms, err)
+	}
+	if engineVersion != "5.3.2" {
+		t.Errorf("Expected the exact engine version in the descriptor, got %q", engineVersion)
+	}
 }
 
 // helper for tests
@@ -252,6 +463,21 @@ func writeBuildScript(engine, version, scriptName string, t *testing.T) string {
 	return makeFile(path, scriptName, t)
 }
 
+// an engine with the build script of every version, a plugin and an output folder, in base
+func writeTestProject(base string, versions []string, t *testing.T) *model.Config {
+	t.Helper()
+	engine := makeDir(base, "Engine", t)
+	for _, version := range versions {
+		writeBuildScript(engine, version, "RunUAT.bat", t)
+	}
+	return &model.Config{
+		EngineBaseDirectory: engine,
+		BuildScriptPath:     "RunUAT.bat",
+		OutputBaseDirectory: makeDir(base, "Output", t),
+		PluginPath:          writeDescriptor(makeDir(base, "Plugin", t), testDescriptor, t),
+	}
+}
+
 func isDirectoryExist(path string) bool {
 	info, err := os.Stat(path)
 	if err != nil {
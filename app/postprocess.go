@@ -0,0 +1,112 @@
+// repeats the steps after the build on releases that are already in the output directory.
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+/*
+Runs the stages after the build again on the existing releases of the selected versions, without building them.
+Versions without a usable release are skipped with a warning.
+Returns how many versions were processed.
+*/
+func (pb *PluginBuilder) PostProcessExistingReleases(ctx context.Context, cmdInput model.CmdInput, execPath string) (int, error) {
+	stages, err := pb.createStages()
+	if err != nil {
+		return 0, fmt.Errorf("invalid stages: %w", err)
+	}
+
+	unlock, err := pb.lockOutputDirectory()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	stages = stages[1:]
+
+	processed := 0
+	for _, version := range pb.collectVersions(cmdInput.EngineVersions) {
+		version = strings.TrimSpace(version)
+		if version == "" {
+			continue
+		}
+
+		release, err := pb.findExistingRelease(version, cmdInput, execPath)
+		if err != nil {
+			return processed, pb.failVersion(ctx, &Release{Version: version}, err)
+		}
+
+		if !hasUsableBuildOutput(pb.fs, release.OutputDir, createPluginName(pb.config.PluginPath)) {
+			pb.log.Warn("skipping version without usable build output", "version", version, "path", release.OutputDir)
+			continue
+		}
+
+		pb.log.Info("post-processing", "version", version, "path", release.OutputDir, "stages", strings.Join(stageNames(stages), " -> "))
+		pb.emit(model.VersionStarted{Version: version, OutputDir: release.OutputDir})
+		if err := pb.stageExistingRelease(release); err != nil {
+			return processed, pb.failVersion(ctx, release, err)
+		}
+
+		if err := pb.runStages(ctx, stages, release); err != nil {
+			return processed, pb.failVersion(ctx, release, err)
+		}
+		pb.emit(model.VersionFinished{Version: version, OutputDir: release.OutputDir})
+		processed++
+	}
+
+	removeEmptyDirectory(pb.guard, pb.makeStagingRoot())
+	pb.saveReport()
+	return processed, nil
+}
+
+/*
+The release of the version already in the output directory. It's the newest release stamped with the version,
+as rendering the output name again gives another name once it uses the date or the commit.
+A release that wasn't stamped is only found by the name a new release would get.
+*/
+func (pb *PluginBuilder) findExistingRelease(version string, cmdInput model.CmdInput, execPath string) (*Release, error) {
+	release, err := pb.newRelease(version, cmdInput, execPath)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := findReleases(pb.fs, pb.config)
+	if err != nil {
+		pb.log.Warn("failed to look for the releases, using the name a new release would get", "error", err)
+		return release, nil
+	}
+	var newest *RetentionEntry
+	for i := range releases {
+		if releases[i].Version == version && (newest == nil || releases[i].ModTime.After(newest.ModTime)) {
+			newest = &releases[i]
+		}
+	}
+	if newest != nil {
+		release.OutputDir = newest.Path
+		release.StagingDir = filepath.Join(pb.makeStagingRoot(), newest.Name)
+	}
+	return release, nil
+}
+
+// copies the published release back into the staging area, so a failure leaves it untouched
+func (pb *PluginBuilder) stageExistingRelease(release *Release) error {
+	if err := createOwnedDirectory(pb.fs, filepath.Dir(release.StagingDir)); err != nil {
+		return fmt.Errorf("failed to create staging folder: %w", err)
+	}
+	if err := copyDirectory(pb.fs, release.OutputDir, release.StagingDir, nil); err != nil {
+		return fmt.Errorf("failed to stage existing release: %w", err)
+	}
+
+	pb.report.Versions = append(pb.report.Versions, model.VersionReport{Version: release.Version, OutputDir: release.StagingDir, Succeeded: true})
+	pb.recordEngineBuild(release)
+	return nil
+}
+
+// a release can be post-processed if the build left the plugin descriptor in it
+func hasUsableBuildOutput(fsys FileSystem, outputDir string, pluginName string) bool {
+	return isFileIn(fsys, filepath.Join(outputDir, pluginName+".uplugin"))
+}
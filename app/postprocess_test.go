@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"unreal-plugin-release/model"
+)
+
+func TestPostProcessShouldSkipVersionsWithoutUsableOutput(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := writeTestProject(base, nil, t)
+	output := config.OutputBaseDirectory
+	release := filepath.Join(output, "MyPlugin_5.4")
+	makeDir(release, "Intermediate", t)
+	makeFile(filepath.Join(release, "Source"), "MyActor.cpp", t)
+	writeDescriptor(release, testDescriptor, t)
+	markOwned(OSFileSystem{}, output, "MyPlugin_5.4")
+	makeDir(output, "MyPlugin_5.3", t)
+	underTest := NewPluginBuilder(config, FakeExecutor{})
+
+	// when
+	processed, err := underTest.PostProcessExistingReleases(context.Background(), model.CmdInput{EngineVersions: "5.3,5.4", SkipDocs: true}, filepath.Join(base, "script.exe"))
+
+	// then
+	if err != nil {
+		t.Fatalf("Post-processing should have succeeded: %v", err)
+	}
+	if processed != 1 {
+		t.Errorf("Only the 5.4 release should have been processed, processed %d", processed)
+	}
+	if IsPathExist(filepath.Join(release, "Intermediate")) || !isFileExist(filepath.Join(release, "Source", "MyActor.cpp")) {
+		t.Error("The existing release should have been cleaned up, keeping its source.")
+	}
+	if !isFileExist(release+".zip") || !isFileExist(release+".zip"+checksumFileExtension) {
+		t.Error("The archive and its checksum should have been published.")
+	}
+	if IsPathExist(filepath.Join(output, "MyPlugin_5.3.zip")) {
+		t.Error("A version without build output should have been skipped.")
+	}
+}
+
+func TestPostProcessShouldFindReleasesNamedOnAnotherDay(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := writeTestProject(base, nil, t)
+	config.OutputNameTemplate = "{{.PluginName}}_UE{{.EngineVersion}}_{{.Date}}"
+	config.Stages = []model.StageConfig{{Name: "build"}, {Name: "archive"}, {Name: "publish"}}
+	output := config.OutputBaseDirectory
+	release := filepath.Join(output, "MyPlugin_UE5.4_2025-01-30")
+	makeDir(output, "MyPlugin_UE5.4_2025-01-30", t)
+	writeDescriptor(release, `{"FileVersion": 3, "EngineVersion": "5.4.1"}`, t)
+	markOwned(OSFileSystem{}, output, "MyPlugin_UE5.4_2025-01-30")
+	underTest := NewPluginBuilder(config, FakeExecutor{})
+	underTest.now = func() time.Time { return time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC) }
+
+	// when
+	processed, err := underTest.PostProcessExistingReleases(context.Background(), model.CmdInput{EngineVersions: "5.4", SkipDocs: true}, filepath.Join(base, "script.exe"))
+
+	// then
+	if err != nil || processed != 1 {
+		t.Fatalf("The release of the day before should have been processed, processed %d: %v", processed, err)
+	}
+	if !isFileExist(release + ".zip") {
+		t.Error("The archive should have been published next to the existing release.")
+	}
+	if IsPathExist(filepath.Join(output, "MyPlugin_UE5.4_2025-01-31")) {
+		t.Error("No release should be named after today.")
+	}
+}
+
+func TestPostProcessShouldRefuseAnEngineOfAnotherVersion(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := writeTestProject(base, nil, t)
+	writeBuildVersion(filepath.Join(config.EngineBaseDirectory, "UE_5.4"), testBuildVersion, t)
+	output := config.OutputBaseDirectory
+	release := filepath.Join(output, "MyPlugin_5.4")
+	writeDescriptor(makeDir(output, "MyPlugin_5.4", t), `{"FileVersion": 3, "EngineVersion": "5.4.0"}`, t)
+	markOwned(OSFileSystem{}, output, "MyPlugin_5.4")
+	underTest := NewPluginBuilder(config, FakeExecutor{})
+
+	// when
+	processed, err := underTest.PostProcessExistingReleases(context.Background(), model.CmdInput{EngineVersions: "5.4", SkipDocs: true}, filepath.Join(base, "script.exe"))
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "is UE 5.3.2, not 5.4") || processed != 0 {
+		t.Errorf("The 5.3 engine in the UE_5.4 folder should be refused, processed %d: %v", processed, err)
+	}
+	if IsPathExist(release + ".zip") {
+		t.Error("The release of the mismatched engine should be left alone.")
+	}
+}
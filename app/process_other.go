@@ -0,0 +1,12 @@
+//go:build !windows
+
+package app
+
+import "syscall"
+
+// whether a process with the id runs on this machine
+func isProcessRunning(pid int) bool {
+	// signal 0 only checks the process, EPERM means it exists but belongs to someone else
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,24 @@
+package app
+
+import "syscall"
+
+const processQueryLimitedInformation = 0x1000
+
+// the exit code of a process that hasn't exited yet
+const stillActive = 259
+
+// whether a process with the id runs on this machine
+func isProcessRunning(pid int) bool {
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// the process exists, it just belongs to someone else
+		return err == syscall.ERROR_ACCESS_DENIED
+	}
+	defer syscall.CloseHandle(handle)
+
+	var exitCode uint32
+	if err := syscall.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return true
+	}
+	return exitCode == stillActive
+}
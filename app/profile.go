@@ -0,0 +1,55 @@
+// applies the named profiles of the config, which override parts of it for a kind of release.
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+/*
+Overlays the named profile on the config. Only the fields set in the profile change,
+maps are merged key by key, everything else is replaced. An empty name leaves the config as it is.
+*/
+func ApplyProfile(config *model.Config, profileName string) error {
+	if profileName == "" {
+		return nil
+	}
+
+	profile, ok := config.Profiles[profileName]
+	if !ok {
+		return fmt.Errorf("profile %q not found, available profiles: %s", profileName, strings.Join(profileNames(config), ", "))
+	}
+
+	return overlayConfig(config, profile, fmt.Sprintf("profile %q", profileName))
+}
+
+// decodes the fields in the data over the config, rejecting unknown ones, so a typo doesn't silently leave a setting out.
+// The values given per OS are replaced with the one of the running OS first.
+func overlayConfig(config *model.Config, data []byte, source string) error {
+	data, err := selectPlatformValues(data, runtime.GOOS)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return describeDecodeError(err, source)
+	}
+	return nil
+}
+
+func profileNames(config *model.Config) []string {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestApplyProfileShouldOnlyOverrideSetFields(t *testing.T) {
+	// given
+	config := model.Config{}
+	data := `{
+		"outputBaseDirectory": "Releases",
+		"docsPath": "Docs.pdf",
+		"extraUatArgs": {"*": ["-StrictIncludes"]},
+		"profiles": {
+			"fab": {"outputBaseDirectory": "Fab", "extraUatArgs": {">=5.3": ["-VS2022"]}}
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	// when
+	err := ApplyProfile(&config, "fab")
+
+	// then
+	if err != nil {
+		t.Fatalf("The profile should be applied: %v", err)
+	}
+	if config.OutputBaseDirectory != "Fab" || config.DocsPath != "Docs.pdf" {
+		t.Errorf("Only the profile's fields should change, got %+v", config)
+	}
+	if len(config.ExtraUatArgs) != 2 {
+		t.Errorf("Maps should be merged, got %v", config.ExtraUatArgs)
+	}
+}
+
+func TestApplyUnknownProfileShouldReturnError(t *testing.T) {
+	// given
+	config := model.Config{Profiles: map[string]json.RawMessage{"fab": json.RawMessage(`{}`)}}
+
+	// when
+	err := ApplyProfile(&config, "website")
+
+	// then
+	if err == nil {
+		t.Error("An unknown profile should be reported.")
+	}
+}
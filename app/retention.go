@@ -0,0 +1,220 @@
+// removes old releases from the output directory, keeping the last ones of every engine version, the recent ones and the published ones.
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"unreal-plugin-release/model"
+)
+
+// a release in the output directory, and whether the retention removes it
+type RetentionEntry struct {
+	Name string
+	Path string
+	// MAJOR.MINOR, from the descriptor of the release
+	Version string
+	ModTime time.Time
+	// the release folder, its archive and checksum
+	Files  []string
+	Delete bool
+	Reason string
+}
+
+/*
+Plans which releases of the output directory the retention removes, newest first by engine version.
+The last keepLast of every version are always kept, the ones beyond them are removed, or only if they're older than maxAgeDays when it's set too.
+With maxAgeDays alone, the newest release of a version is kept however old it is, unless removeLastOfVersion is set.
+The ones tagged as published, and everything the deletion guard refuses are kept.
+*/
+func PlanRetention(config *model.Config, retention model.RetentionConfig, now time.Time) ([]RetentionEntry, error) {
+	guard := NewDeletionGuard(config)
+	guard.log = NewDiscardLogger()
+	return planRetention(guard, config, retention, now)
+}
+
+/*
+Removes the releases the plan deletes, through the deletion guard, returning how many were removed.
+The output directory is locked meanwhile, so a running batch isn't cleaned under it.
+*/
+func ApplyRetention(config *model.Config, plan []RetentionEntry, logger *slog.Logger) (int, error) {
+	guard := NewDeletionGuard(config)
+	if logger != nil {
+		guard.log = logger
+	}
+	lock, err := acquireOutputLock(guard.fs, config.OutputBaseDirectory, guard.log)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.release()
+	return applyRetention(guard, plan), nil
+}
+
+/*
+Tags the release in the output directory as published, so the retention always keeps it.
+*/
+func TagPublished(config *model.Config, name string) error {
+	path := filepath.Join(config.OutputBaseDirectory, name)
+	if !isDirectory(path) {
+		return fmt.Errorf("release %s not found in %s", name, config.OutputBaseDirectory)
+	}
+	fsys := OSFileSystem{}
+	if err := fsys.WriteFile(path+model.PublishedTagExtension, nil, 0644); err != nil {
+		return fmt.Errorf("failed to tag %s as published: %w", name, err)
+	}
+	return markOwned(fsys, config.OutputBaseDirectory, name+model.PublishedTagExtension)
+}
+
+func planRetention(guard *DeletionGuard, config *model.Config, retention model.RetentionConfig, now time.Time) ([]RetentionEntry, error) {
+	if retention.KeepLast < 0 || retention.MaxAgeDays < 0 {
+		return nil, errors.New("keepLast and maxAgeDays can't be negative")
+	}
+	if retention.KeepLast == 0 && retention.MaxAgeDays == 0 {
+		return nil, errors.New("no retention set, set keepLast or maxAgeDays")
+	}
+
+	entries, err := findReleases(guard.fs, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// newest first within every version
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Version != entries[j].Version {
+			return entries[i].Version < entries[j].Version
+		}
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+
+	maxAge := time.Duration(retention.MaxAgeDays) * 24 * time.Hour
+	// the number of releases of every version the age never removes
+	floor := retention.KeepLast
+	if floor == 0 && !retention.RemoveLastOfVersion {
+		floor = 1
+	}
+	rank := map[string]int{}
+	for i := range entries {
+		entry := &entries[i]
+		position := rank[entry.Version]
+		rank[entry.Version]++
+
+		age := now.Sub(entry.ModTime)
+		switch {
+		case pathExists(guard.fs, entry.Path+model.PublishedTagExtension):
+			entry.Reason = "tagged as published"
+		case position < floor && retention.KeepLast == 0:
+			entry.Reason = fmt.Sprintf("the newest of UE %s", entry.Version)
+		case position < floor:
+			entry.Reason = fmt.Sprintf("among the last %d of UE %s", retention.KeepLast, entry.Version)
+		case retention.MaxAgeDays > 0 && age > maxAge:
+			entry.Delete, entry.Reason = true, fmt.Sprintf("older than %d days", retention.MaxAgeDays)
+		case retention.MaxAgeDays > 0:
+			entry.Reason = fmt.Sprintf("newer than %d days", retention.MaxAgeDays)
+		default:
+			entry.Delete, entry.Reason = true, fmt.Sprintf("not among the last %d of UE %s", retention.KeepLast, entry.Version)
+		}
+
+		if entry.Delete {
+			if reason := guard.refusalReason(entry.Path); reason != "" {
+				entry.Delete, entry.Reason = false, "can't be deleted, "+reason
+			}
+		}
+	}
+	return entries, nil
+}
+
+func applyRetention(guard *DeletionGuard, plan []RetentionEntry) int {
+	deleted := 0
+	for _, entry := range plan {
+		if !entry.Delete {
+			continue
+		}
+		// the folder last, so a release that can't be fully removed is still recognized by the next clean
+		removed := true
+		for i := len(entry.Files) - 1; i >= 0; i-- {
+			removed = guard.Remove(entry.Files[i]) && removed
+		}
+		if removed {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// the owned folders of the output directory that hold a stamped descriptor of the plugin
+func findReleases(fsys FileSystem, config *model.Config) ([]RetentionEntry, error) {
+	dirEntries, err := fsys.ReadDir(config.OutputBaseDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the releases: %w", err)
+	}
+
+	descriptorName := filepath.Base(config.PluginPath)
+	var releases []RetentionEntry
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() || strings.HasPrefix(dirEntry.Name(), ".") || dirEntry.Name() == model.LogsDirectoryName {
+			continue
+		}
+
+		path := filepath.Join(config.OutputBaseDirectory, dirEntry.Name())
+		version, ok := releaseEngineVersion(fsys, filepath.Join(path, descriptorName))
+		if !ok {
+			continue
+		}
+		info, err := fsys.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		files := []string{path}
+		for _, suffix := range releaseFileSuffixes {
+			if pathExists(fsys, path+suffix) {
+				files = append(files, path+suffix)
+			}
+		}
+		releases = append(releases, RetentionEntry{Name: dirEntry.Name(), Path: path, Version: version, ModTime: info.ModTime(), Files: files})
+	}
+	return releases, nil
+}
+
+// the MAJOR.MINOR the descriptor was stamped with, the release of another plugin or an unstamped one has none
+func releaseEngineVersion(fsys FileSystem, descriptorPath string) (string, bool) {
+	descriptor, err := readPluginDescriptor(fsys, descriptorPath)
+	if err != nil {
+		return "", false
+	}
+
+	var engineVersion string
+	if found, err := descriptor.getValue("EngineVersion", &engineVersion); !found || err != nil {
+		return "", false
+	}
+	parts := strings.Split(engineVersion, ".")
+	if len(parts) < 2 {
+		return "", false
+	}
+	return parts[0] + "." + parts[1], true
+}
+
+// applies the retention of the config after a successful batch, logging the plan first
+func (pb *PluginBuilder) applyRetentionAfterBatch() {
+	retention := pb.config.Retention
+	if retention == nil || !retention.AfterBatch {
+		return
+	}
+
+	plan, err := planRetention(pb.guard, pb.config, *retention, pb.now())
+	if err != nil {
+		pb.log.Warn("retention skipped", "error", err)
+		return
+	}
+	for _, entry := range plan {
+		pb.log.Info("retention", "release", entry.Name, "version", entry.Version, "delete", entry.Delete, "reason", entry.Reason)
+	}
+	if deleted := applyRetention(pb.guard, plan); deleted > 0 {
+		pb.log.Info("old releases removed", "count", deleted)
+	}
+}
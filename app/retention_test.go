@@ -0,0 +1,182 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"unreal-plugin-release/model"
+)
+
+func TestPlanRetentionShouldKeepTheLastReleasesOfEveryVersion(t *testing.T) {
+	// given
+	config, now := writeReleases([]testRelease{
+		{name: "MyPlugin_1.3_UE5.4", engineVersion: "5.4.0", age: 1},
+		{name: "MyPlugin_1.2_UE5.4", engineVersion: "5.4.0", age: 10},
+		{name: "MyPlugin_1.1_UE5.4", engineVersion: "5.4.0", age: 20, published: true},
+		{name: "MyPlugin_1.0_UE5.4", engineVersion: "5.4.0", age: 30},
+		{name: "MyPlugin_1.0_UE5.3", engineVersion: "5.3.2", age: 30},
+		{name: "Unowned_UE5.4", engineVersion: "5.4.0", age: 40, unowned: true},
+	}, t)
+
+	// when
+	plan, err := PlanRetention(config, model.RetentionConfig{KeepLast: 2}, now)
+
+	// then
+	if err != nil {
+		t.Fatalf("The plan should be made: %v", err)
+	}
+	deleted := map[string]bool{}
+	for _, entry := range plan {
+		deleted[entry.Name] = entry.Delete
+	}
+	expected := map[string]bool{
+		"MyPlugin_1.3_UE5.4": false,
+		"MyPlugin_1.2_UE5.4": false,
+		"MyPlugin_1.1_UE5.4": false,
+		"MyPlugin_1.0_UE5.4": true,
+		"MyPlugin_1.0_UE5.3": false,
+		"Unowned_UE5.4":      false,
+	}
+	for name, expectedDelete := range expected {
+		if deleted[name] != expectedDelete {
+			t.Errorf("%s should be deleted: %v, the plan is %+v", name, expectedDelete, plan)
+		}
+	}
+}
+
+func TestPlanRetentionShouldRemoveOldReleases(t *testing.T) {
+	// given
+	config, now := writeReleases([]testRelease{
+		{name: "MyPlugin_1.1_UE5.4", engineVersion: "5.4", age: 100},
+		{name: "MyPlugin_1.0_UE5.4", engineVersion: "5.4", age: 120},
+		{name: "MyPlugin_0.9_UE5.4", engineVersion: "5.4", age: 5},
+	}, t)
+
+	// when
+	plan, err := PlanRetention(config, model.RetentionConfig{MaxAgeDays: 90}, now)
+
+	// then
+	if err != nil {
+		t.Fatalf("The plan should be made: %v", err)
+	}
+	if plan[0].Name != "MyPlugin_0.9_UE5.4" || plan[0].Delete || !plan[1].Delete || !plan[2].Delete {
+		t.Errorf("Only the releases older than 90 days should be deleted, got %+v", plan)
+	}
+}
+
+func TestPlanRetentionShouldKeepTheNewestReleaseEvenIfItIsOld(t *testing.T) {
+	// given
+	config, now := writeReleases([]testRelease{
+		{name: "MyPlugin_1.0_UE5.3", engineVersion: "5.3", age: 120},
+		{name: "MyPlugin_0.9_UE5.3", engineVersion: "5.3", age: 150},
+	}, t)
+
+	// when
+	kept, err := PlanRetention(config, model.RetentionConfig{MaxAgeDays: 90}, now)
+	removed, _ := PlanRetention(config, model.RetentionConfig{MaxAgeDays: 90, RemoveLastOfVersion: true}, now)
+
+	// then
+	if err != nil {
+		t.Fatalf("The plan should be made: %v", err)
+	}
+	if kept[0].Delete || !kept[1].Delete {
+		t.Errorf("The newest release of UE 5.3 should be kept, even if it's older than 90 days, got %+v", kept)
+	}
+	if !removed[0].Delete || !removed[1].Delete {
+		t.Errorf("With removeLastOfVersion, every release older than 90 days should be deleted, got %+v", removed)
+	}
+}
+
+func TestPlanRetentionShouldNotRemoveTheLastReleasesByAge(t *testing.T) {
+	// given
+	config, now := writeReleases([]testRelease{
+		{name: "MyPlugin_1.2_UE5.4", engineVersion: "5.4", age: 100},
+		{name: "MyPlugin_1.1_UE5.4", engineVersion: "5.4", age: 110},
+		{name: "MyPlugin_1.0_UE5.4", engineVersion: "5.4", age: 120},
+		{name: "MyPlugin_1.0_UE5.3", engineVersion: "5.3", age: 10},
+		{name: "MyPlugin_0.9_UE5.3", engineVersion: "5.3", age: 20},
+		{name: "MyPlugin_0.8_UE5.3", engineVersion: "5.3", age: 30},
+	}, t)
+
+	// when
+	plan, err := PlanRetention(config, model.RetentionConfig{KeepLast: 2, MaxAgeDays: 90, RemoveLastOfVersion: true}, now)
+
+	// then
+	if err != nil {
+		t.Fatalf("The plan should be made: %v", err)
+	}
+	for _, entry := range plan {
+		if entry.Delete != (entry.Name == "MyPlugin_1.0_UE5.4") {
+			t.Errorf("Only the release beyond the last 2 of its version and older than 90 days should be deleted, got %+v", plan)
+			break
+		}
+	}
+}
+
+func TestApplyRetentionShouldRemoveTheReleaseWithItsArchive(t *testing.T) {
+	// given
+	config, now := writeReleases([]testRelease{
+		{name: "MyPlugin_1.1_UE5.4", engineVersion: "5.4.0", age: 1},
+		{name: "MyPlugin_1.0_UE5.4", engineVersion: "5.4.0", age: 2},
+	}, t)
+	plan, err := PlanRetention(config, model.RetentionConfig{KeepLast: 1}, now)
+	if err != nil {
+		t.Fatalf("The plan should be made: %v", err)
+	}
+
+	// when
+	removed, err := ApplyRetention(config, plan, NewDiscardLogger())
+
+	// then
+	if err != nil {
+		t.Fatalf("The retention should be applied: %v", err)
+	}
+	old := filepath.Join(config.OutputBaseDirectory, "MyPlugin_1.0_UE5.4")
+	if removed != 1 || IsPathExist(old) || IsPathExist(old+".zip") {
+		t.Errorf("The old release and its archive should be removed, %d removed", removed)
+	}
+	if !IsPathExist(filepath.Join(config.OutputBaseDirectory, "MyPlugin_1.1_UE5.4.zip")) {
+		t.Error("The newest release should be kept.")
+	}
+}
+
+type testRelease struct {
+	name          string
+	engineVersion string
+	// in days
+	age       int
+	published bool
+	unowned   bool
+}
+
+// published releases of MyPlugin in an output directory, with their archives
+func writeReleases(releases []testRelease, t *testing.T) (*model.Config, time.Time) {
+	t.Helper()
+	base := t.TempDir()
+	output := makeDir(base, "Output", t)
+	config := &model.Config{OutputBaseDirectory: output, PluginPath: filepath.Join(base, "Plugin", "MyPlugin.uplugin")}
+	now := time.Now()
+
+	for _, release := range releases {
+		dir := makeDir(output, release.name, t)
+		descriptor := `{"FileVersion": 3, "EngineVersion": "` + release.engineVersion + `"}`
+		if err := os.WriteFile(filepath.Join(dir, "MyPlugin.uplugin"), []byte(descriptor), 0644); err != nil {
+			t.Fatalf("Failed to write descriptor: %v", err)
+		}
+		makeFile(output, release.name+".zip", t)
+		if !release.unowned {
+			markOwned(OSFileSystem{}, output, release.name)
+			markOwned(OSFileSystem{}, output, release.name+".zip")
+		}
+		if release.published {
+			makeFile(output, release.name+model.PublishedTagExtension, t)
+		}
+		modified := now.Add(-time.Duration(release.age) * 24 * time.Hour)
+		if err := os.Chtimes(dir, modified, modified); err != nil {
+			t.Fatalf("Failed to set the modification time: %v", err)
+		}
+	}
+	return config, now
+}
@@ -0,0 +1,159 @@
+// handles repeating builds that failed for transient reasons, like locked files or the compiler running out of heap.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"unreal-plugin-release/model"
+)
+
+// log lines of failures that are usually gone on the next attempt
+var defaultRetryablePatterns = []string{
+	`LNK1201`,
+	`C1060`,
+	`C1076`,
+	`C3859`,
+	`out of heap space`,
+	`being used by another process`,
+	`Access to the path .* is denied`,
+}
+
+/*
+Runs the build for a version until it succeeds, fails with a non-retryable error, or runs out of attempts.
+Every attempt's output is kept in its own log file, and every retry starts with the output of the failed attempt removed.
+*/
+func (pb *PluginBuilder) buildWithRetries(ctx context.Context, version, buildScriptPath, outputDir, pluginPath string, args []string) (model.VersionReport, error) {
+	report := model.VersionReport{Version: version, OutputDir: outputDir}
+	retry := pb.retryConfigFor(version)
+
+	patterns, err := compileRetryablePatterns(retry.RetryablePatterns)
+	if err != nil {
+		return report, err
+	}
+
+	for attempt := 1; attempt <= retry.MaxAttempts; attempt++ {
+		logPath := pb.makeAttemptLogPath(version, attempt)
+		runErr := pb.runBuildAttempt(ctx, version, buildScriptPath, pluginPath, outputDir, args, logPath)
+		attemptReport := model.AttemptReport{Number: attempt, LogPath: logPath}
+
+		if runErr == nil {
+			report.Attempts = append(report.Attempts, attemptReport)
+			report.Succeeded = true
+			return report, nil
+		}
+
+		attemptReport.Error = runErr.Error()
+		attemptReport.Retryable = ctx.Err() == nil && isRetryableFailure(pb.fs, logPath, patterns)
+		report.Attempts = append(report.Attempts, attemptReport)
+
+		if !attemptReport.Retryable {
+			return report, runErr
+		}
+
+		if attempt < retry.MaxAttempts {
+			backoff := backoffForAttempt(retry.BackoffSeconds, attempt)
+			pb.log.Warn("transient build failure, retrying", "version", version, "attempt", attempt, "maxAttempts", retry.MaxAttempts, "backoff", backoff, "path", logPath)
+			if err := pb.sleep(ctx, backoff); err != nil {
+				return report, err
+			}
+			if pathExists(pb.fs, outputDir) && !pb.guard.Remove(outputDir) {
+				return report, fmt.Errorf("failed to remove the output of the failed attempt %s, not retrying over it", outputDir)
+			}
+		}
+	}
+
+	return report, errors.New("build failed after " + strconv.Itoa(retry.MaxAttempts) + " attempts")
+}
+
+func (pb *PluginBuilder) runBuildAttempt(ctx context.Context, version, buildScriptPath, pluginPath, outputDir string, args []string, logPath string) error {
+	if err := pb.fs.MkdirAll(filepath.Dir(logPath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create log folder: %w", err)
+	}
+	markOwned(pb.fs, pb.config.OutputBaseDirectory, model.LogsDirectoryName)
+
+	logFile, err := pb.fs.Create(logPath)
+	if err != nil {
+		return fmt.Errorf("failed to create build log: %w", err)
+	}
+	defer logFile.Close()
+	pb.emit(model.ArtifactWritten{Kind: model.ArtifactLog, Path: logPath})
+
+	buildCmd := pb.runner.CreateBuilderCommand(buildScriptPath, pluginPath, outputDir, args)
+	flush := forwardOutput(buildCmd, pb.log, "uat", logFile, "version", version)
+	defer flush()
+	return runCommand(ctx, buildCmd)
+}
+
+// the per-version retry settings win over the global ones, with a single attempt if none are set.
+func (pb *PluginBuilder) retryConfigFor(version string) model.RetryConfig {
+	retry := model.RetryConfig{MaxAttempts: 1}
+	if pb.config.Retry != nil {
+		retry = *pb.config.Retry
+	}
+	if versionConfig, ok := pb.config.Versions[version]; ok && versionConfig.Retry != nil {
+		retry = *versionConfig.Retry
+	}
+
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+	if len(retry.RetryablePatterns) == 0 {
+		retry.RetryablePatterns = defaultRetryablePatterns
+	}
+	return retry
+}
+
+func (pb *PluginBuilder) makeAttemptLogPath(version string, attempt int) string {
+	name := createPluginName(pb.config.PluginPath) + "_" + version + "_attempt" + strconv.Itoa(attempt) + ".log"
+	return filepath.Join(pb.config.OutputBaseDirectory, model.LogsDirectoryName, name)
+}
+
+func compileRetryablePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retryable pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, expression)
+	}
+	return compiled, nil
+}
+
+func isRetryableFailure(fsys FileSystem, logPath string, patterns []*regexp.Regexp) bool {
+	logData, err := fsys.ReadFile(logPath)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		if pattern.Match(logData) {
+			return true
+		}
+	}
+	return false
+}
+
+// waits for the duration, or returns the error of the context as soon as it's cancelled
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// doubles the wait after every failed attempt
+func backoffForAttempt(backoffSeconds int, attempt int) time.Duration {
+	return time.Duration(backoffSeconds) * time.Second * time.Duration(1<<(attempt-1))
+}
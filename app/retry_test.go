@@ -0,0 +1,222 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"unreal-plugin-release/model"
+)
+
+// fake executor whose builds fail with the given log line until the failures run out.
+type FailingExecutor struct {
+	FakeExecutor
+	failures *int
+	message  string
+}
+
+func (e FailingExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string, extraArgs []string) *exec.Cmd {
+	if *e.failures > 0 {
+		*e.failures--
+		return createFailingCommand(e.message)
+	}
+	return e.FakeExecutor.CreateBuilderCommand(buildScriptPath, pluginLocation, outputDir, extraArgs)
+}
+
+func createFailingCommand(message string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/c", "echo "+message+" & exit /b 1")
+	}
+	return exec.Command("sh", "-c", "echo '"+message+"'; exit 1")
+}
+
+func TestTransientFailureShouldBeRetried(t *testing.T) {
+	// given
+	base := t.TempDir()
+	failures := 1
+	config := model.Config{
+		OutputBaseDirectory: base,
+		PluginPath:          filepath.Join(base, "MyPlugin.uplugin"),
+		Retry:               &model.RetryConfig{MaxAttempts: 3, BackoffSeconds: 1},
+	}
+	underTest := NewPluginBuilder(&config, FailingExecutor{failures: &failures, message: "LINK : fatal error LNK1201: error writing to program database"})
+	var slept []time.Duration
+	underTest.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+
+	// when
+	report, err := underTest.buildWithRetries(context.Background(), "5.4", "RunUAT.bat", filepath.Join(base, "MyPlugin_5.4"), config.PluginPath, nil)
+
+	// then
+	if err != nil {
+		t.Fatalf("The second attempt should have succeeded, got %v", err)
+	}
+	if len(report.Attempts) != 2 || !report.Attempts[0].Retryable || !report.Succeeded {
+		t.Errorf("Expected a retryable failure followed by a success, got %+v", report)
+	}
+	if len(slept) != 1 || slept[0] != time.Second {
+		t.Errorf("Expected a single one second backoff, got %v", slept)
+	}
+	for _, attempt := range report.Attempts {
+		if !isFileExist(attempt.LogPath) {
+			t.Errorf("Log of attempt %d should have been kept.", attempt.Number)
+		}
+	}
+}
+
+func TestCompileErrorShouldFailFast(t *testing.T) {
+	// given
+	base := t.TempDir()
+	failures := 1
+	config := model.Config{
+		OutputBaseDirectory: base,
+		PluginPath:          filepath.Join(base, "MyPlugin.uplugin"),
+		Retry:               &model.RetryConfig{MaxAttempts: 3},
+	}
+	underTest := NewPluginBuilder(&config, FailingExecutor{failures: &failures, message: "MyActor.cpp(12): error C2065: undeclared identifier"})
+	underTest.sleep = func(context.Context, time.Duration) error {
+		t.Error("A compile error must not wait for a retry.")
+		return nil
+	}
+
+	// when
+	report, err := underTest.buildWithRetries(context.Background(), "5.4", "RunUAT.bat", filepath.Join(base, "MyPlugin_5.4"), config.PluginPath, nil)
+
+	// then
+	if err == nil {
+		t.Error("A compile error should fail the build.")
+	}
+	if len(report.Attempts) != 1 || report.Attempts[0].Retryable {
+		t.Errorf("Expected a single non-retryable attempt, got %+v", report.Attempts)
+	}
+}
+
+func TestRetryShouldRemoveTheOutputOfTheFailedAttempt(t *testing.T) {
+	// given
+	base := t.TempDir()
+	stagingRoot := filepath.Join(base, model.StagingDirectoryName)
+	if err := createOwnedDirectory(OSFileSystem{}, stagingRoot); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(stagingRoot, "MyPlugin_5.4")
+	makeFile(outputDir, "HalfWritten.uasset", t)
+	failures := 1
+	config := model.Config{
+		OutputBaseDirectory: base,
+		PluginPath:          filepath.Join(base, "MyPlugin.uplugin"),
+		Retry:               &model.RetryConfig{MaxAttempts: 2},
+	}
+	underTest := NewPluginBuilder(&config, FailingExecutor{failures: &failures, message: "The process cannot access the file because it is being used by another process"})
+	underTest.sleep = func(context.Context, time.Duration) error { return nil }
+
+	// when
+	_, err := underTest.buildWithRetries(context.Background(), "5.4", "RunUAT.bat", outputDir, config.PluginPath, nil)
+
+	// then
+	if err != nil {
+		t.Fatalf("The retry should have succeeded, got %v", err)
+	}
+	if isFileExist(filepath.Join(outputDir, "HalfWritten.uasset")) {
+		t.Error("The retry should not build over the output of the failed attempt.")
+	}
+	if !IsPathExist(filepath.Join(outputDir, "Source")) {
+		t.Error("The output of the retry should be kept.")
+	}
+}
+
+func TestBackoffShouldStopOnCancel(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// when
+	started := time.Now()
+	err := sleepContext(ctx, time.Hour)
+
+	// then
+	if err == nil || time.Since(started) > time.Second {
+		t.Errorf("A cancelled wait should return right away with the error, got %v after %s", err, time.Since(started))
+	}
+}
+
+func TestValidateSettingsShouldRejectInvalidRetryablePattern(t *testing.T) {
+	// given
+	config := model.Config{Versions: map[string]model.VersionConfig{"5.4": {Retry: &model.RetryConfig{RetryablePatterns: []string{"LNK1201", "C1060("}}}}}
+
+	// when
+	problems := ValidateSettings(&config)
+
+	// then
+	if len(problems) != 1 || problems[0].Field != "versions.5.4.retry.retryablePatterns" || problems[0].Value != "C1060(" {
+		t.Errorf("The invalid pattern should be reported before the batch, got %v", problems)
+	}
+}
+
+func TestRetryConfigForVersionShouldPreferVersionOverride(t *testing.T) {
+	// given
+	config := model.Config{
+		Retry: &model.RetryConfig{MaxAttempts: 2},
+		Versions: map[string]model.VersionConfig{
+			"5.4": {Retry: &model.RetryConfig{MaxAttempts: 5, RetryablePatterns: []string{"flaky"}}},
+		},
+	}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	overridden := underTest.retryConfigFor("5.4")
+	global := underTest.retryConfigFor("5.3")
+
+	// then
+	if overridden.MaxAttempts != 5 || len(overridden.RetryablePatterns) != 1 {
+		t.Errorf("Version override was not applied: %+v", overridden)
+	}
+	if global.MaxAttempts != 2 || len(global.RetryablePatterns) != len(defaultRetryablePatterns) {
+		t.Errorf("Global retry settings with default patterns expected: %+v", global)
+	}
+}
+
+func TestBackoffShouldDoubleAfterEveryAttempt(t *testing.T) {
+	// when
+	actual := []time.Duration{backoffForAttempt(5, 1), backoffForAttempt(5, 2), backoffForAttempt(5, 3)}
+
+	// then
+	expected := []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("Attempt %d: expected %s, actual %s", i+1, expected[i], actual[i])
+		}
+	}
+}
+
+func TestInvalidRetryablePatternShouldReturnError(t *testing.T) {
+	// when
+	_, err := compileRetryablePatterns([]string{"("})
+
+	// then
+	if err == nil {
+		t.Error("An invalid regex must be reported.")
+	}
+}
+
+func TestIsRetryableFailureShouldMatchLogContents(t *testing.T) {
+	// given
+	logPath := filepath.Join(t.TempDir(), "build.log")
+	if err := os.WriteFile(logPath, []byte("fatal error C1060: compiler is out of heap space"), 0644); err != nil {
+		t.Fatal("Failed to write log")
+	}
+	patterns, _ := compileRetryablePatterns(defaultRetryablePatterns)
+
+	// when
+	actual := isRetryableFailure(OSFileSystem{}, logPath, patterns)
+
+	// then
+	if !actual {
+		t.Error("An out of heap failure should be retryable.")
+	}
+}
@@ -0,0 +1,212 @@
+// decides what the application is allowed to delete, on every OS.
+package app
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+// marks the directory holding the marker file as created by the tool, along with everything inside it
+const ownedDirectoryEntry = "."
+
+/*
+Only lets the tool delete what it created itself, which it records in marker files.
+A marker file lists the entries of its directory that the tool created, or the directory itself.
+Even owned paths are refused if they are filesystem roots, home directories, or contain the engine, the plugin source or the output base.
+*/
+type DeletionGuard struct {
+	protectedPaths []string
+	fs             FileSystem
+	log            *slog.Logger
+}
+
+/*
+Constructor for the deletion guard, protecting the engines, the plugin source, the output base and home directories.
+*/
+func NewDeletionGuard(config *model.Config) *DeletionGuard {
+	return newDeletionGuard(config, OSFileSystem{})
+}
+
+func newDeletionGuard(config *model.Config, fsys FileSystem) *DeletionGuard {
+	guard := &DeletionGuard{fs: fsys, log: defaultLogger()}
+	guard.protectPath(config.EngineBaseDirectory)
+	for version := range config.Engines {
+		guard.protectPath(resolveMappedEngine(fsys, config, version).Root)
+	}
+	if config.PluginPath != "" {
+		guard.protectPath(filepath.Dir(config.PluginPath))
+	}
+	guard.protectPath(config.OutputBaseDirectory)
+	if home, err := os.UserHomeDir(); err == nil {
+		guard.protectPath(home)
+	}
+	return guard
+}
+
+/*
+Removes the path with everything in it, if the deletion policy allows it. Every refusal is logged.
+*/
+func (g *DeletionGuard) Remove(path string) bool {
+	if reason := g.refusalReason(path); reason != "" {
+		g.log.Warn("refusing to delete", "path", path, "reason", reason)
+		return false
+	}
+
+	if err := g.fs.RemoveAll(path); err != nil {
+		g.log.Warn("failed to delete", "path", path, "error", err)
+		return false
+	}
+	return true
+}
+
+// the protected path and its parents cannot be deleted, but its contents can
+func (g *DeletionGuard) protectPath(path string) {
+	if path != "" {
+		g.protectedPaths = append(g.protectedPaths, normalizePath(path))
+	}
+}
+
+// the reason the path must not be deleted, or empty if it can be
+func (g *DeletionGuard) refusalReason(path string) string {
+	if strings.TrimSpace(path) == "" {
+		return "empty path"
+	}
+
+	normalized := normalizePath(path)
+	if isFilesystemRoot(normalized) {
+		return "it is a filesystem root"
+	}
+
+	if isHomeDirectory(normalized) {
+		return "it is or contains a home directory"
+	}
+
+	for _, protected := range g.protectedPaths {
+		if isWithinPath(protected, normalized) {
+			return "it contains the protected directory " + protected
+		}
+	}
+
+	if !isOwnedByTool(g.fs, path) {
+		return "it was not created by this tool"
+	}
+
+	return ""
+}
+
+/*
+Records in the directory's marker file that the named entry was created by the tool.
+Use ownedDirectoryEntry as the name to mark the directory itself.
+*/
+func markOwned(fsys FileSystem, dir string, name string) error {
+	markerPath := filepath.Join(dir, model.OwnershipMarkerFile)
+	entries := readOwnershipMarker(fsys, dir)
+	if slices.Contains(entries, name) {
+		return nil
+	}
+
+	entries = append(entries, name)
+	return fsys.WriteFile(markerPath, []byte(strings.Join(entries, "\n")+"\n"), 0644)
+}
+
+// a path is owned if it, or one of its parents, is marked as created by the tool
+func isOwnedByTool(fsys FileSystem, path string) bool {
+	current, err := filepath.Abs(filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+
+	for {
+		if slices.Contains(readOwnershipMarker(fsys, current), ownedDirectoryEntry) {
+			return true
+		}
+
+		parent := filepath.Dir(current)
+		if slices.Contains(readOwnershipMarker(fsys, parent), filepath.Base(current)) {
+			return true
+		}
+
+		if parent == current {
+			return false
+		}
+		current = parent
+	}
+}
+
+func readOwnershipMarker(fsys FileSystem, dir string) []string {
+	data, err := fsys.ReadFile(filepath.Join(dir, model.OwnershipMarkerFile))
+	if err != nil {
+		return nil
+	}
+
+	var entries []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if entry := strings.TrimSpace(line); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func isFilesystemRoot(normalizedPath string) bool {
+	return filepath.Dir(normalizedPath) == normalizedPath
+}
+
+/*
+The current user's home or a folder containing it, a folder holding the homes of the users, like /home or C:\Users,
+and every home in it. The other folders next to the current user's home are not homes, like /builds next to /root.
+*/
+func isHomeDirectory(normalizedPath string) bool {
+	if home, err := os.UserHomeDir(); err == nil && isWithinPath(normalizePath(home), normalizedPath) {
+		return true
+	}
+
+	for _, usersRoot := range usersRoots() {
+		if normalizedPath == usersRoot || filepath.Dir(normalizedPath) == usersRoot {
+			return true
+		}
+	}
+	return false
+}
+
+// the folders holding the homes of the users on the running OS
+func usersRoots() []string {
+	switch runtime.GOOS {
+	case "windows":
+		systemDrive := os.Getenv("SystemDrive")
+		if systemDrive == "" {
+			systemDrive = "C:"
+		}
+		return []string{normalizePath(systemDrive + `\Users`)}
+	case "darwin":
+		return []string{"/Users"}
+	default:
+		return []string{"/home"}
+	}
+}
+
+// determines if the child is the parent itself or somewhere inside it
+func isWithinPath(child string, parent string) bool {
+	rel, err := filepath.Rel(parent, child)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+func normalizePath(path string) string {
+	normalized, err := filepath.Abs(filepath.Clean(path))
+	if err != nil {
+		normalized = filepath.Clean(path)
+	}
+	if runtime.GOOS == "windows" {
+		normalized = strings.ToLower(normalized)
+	}
+	return normalized
+}
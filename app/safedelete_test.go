@@ -0,0 +1,137 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+type refusalTestData struct {
+	path    string
+	refused bool
+}
+
+func TestDeletionGuardRefusals(t *testing.T) {
+	base := t.TempDir()
+	engine := makeDir(base, "Engine", t)
+	plugin := makeFile(base, filepath.Join("Project", "Plugins", "MyPlugin", "MyPlugin.uplugin"), t)
+	output := makeDir(base, "Output", t)
+	createOwnedDirectory(OSFileSystem{}, filepath.Join(output, ".staging"))
+	makeDir(engine, "UE_5.4", t)
+	underTest := NewDeletionGuard(&model.Config{
+		EngineBaseDirectory: engine,
+		PluginPath:          plugin,
+		OutputBaseDirectory: output,
+	})
+
+	for i, tt := range createRefusalTestData(base, engine, plugin, output) {
+		t.Run("RefusalTest #"+strconv.Itoa(i), func(t *testing.T) {
+			// when
+			reason := underTest.refusalReason(tt.path)
+
+			// then
+			if tt.refused != (reason != "") {
+				t.Errorf("%d case %q: expected refused: %v, reason: %q", i, tt.path, tt.refused, reason)
+			}
+		})
+	}
+}
+
+func TestRemoveShouldKeepRefusedPath(t *testing.T) {
+	// given
+	base := t.TempDir()
+	engine := makeDir(base, "Engine", t)
+	createOwnedDirectory(OSFileSystem{}, engine)
+	underTest := NewDeletionGuard(&model.Config{EngineBaseDirectory: engine})
+
+	// when
+	actual := underTest.Remove(engine)
+
+	// then
+	if actual || !isDirectoryExist(engine) {
+		t.Error("The engine base directory must never be deleted, even if marked as owned.")
+	}
+}
+
+func TestMarkOwnedShouldNotDuplicateEntries(t *testing.T) {
+	// given
+	dir := t.TempDir()
+
+	// when
+	markOwned(OSFileSystem{}, dir, "MyPlugin_5.4")
+	markOwned(OSFileSystem{}, dir, "MyPlugin_5.4")
+
+	// then
+	entries := readOwnershipMarker(OSFileSystem{}, dir)
+	if len(entries) != 1 || entries[0] != "MyPlugin_5.4" {
+		t.Errorf("Expected a single entry, got %v", entries)
+	}
+}
+
+func TestOwnershipShouldBeInheritedFromParents(t *testing.T) {
+	// given
+	base := t.TempDir()
+	createOwnedDirectory(OSFileSystem{}, filepath.Join(base, "Owned"))
+	nested := makeDir(filepath.Join(base, "Owned", "Release"), "Binaries", t)
+	listed := makeDir(base, "Listed", t)
+	markOwned(OSFileSystem{}, base, "Listed")
+	foreign := makeDir(base, "Foreign", t)
+
+	// then
+	if !isOwnedByTool(OSFileSystem{}, nested) {
+		t.Error("Contents of an owned directory should be owned.")
+	}
+	if !isOwnedByTool(OSFileSystem{}, filepath.Join(listed, "Anything")) {
+		t.Error("Contents of an entry listed in the parent's marker should be owned.")
+	}
+	if isOwnedByTool(OSFileSystem{}, foreign) {
+		t.Error("A directory without a marker should not be owned.")
+	}
+}
+
+// test data
+func createRefusalTestData(base, engine, plugin, output string) []refusalTestData {
+	root := filepath.VolumeName(base) + string(os.PathSeparator)
+	home, _ := os.UserHomeDir()
+	return []refusalTestData{
+		{root, true},
+		{home, true},
+		{engine, true},
+		{filepath.Join(engine, "UE_5.4"), true},
+		{filepath.Dir(plugin), true},
+		{filepath.Join(filepath.Dir(plugin), "Binaries"), true},
+		{base, true},
+		{output, true},
+		{filepath.Join(output, "MyPlugin_5.4"), true},
+		{filepath.Join(output, ".staging"), false},
+		{filepath.Join(output, ".staging", "MyPlugin_5.4", "Binaries"), false},
+	}
+}
+
+// a guard that only applies the ownership rules, without protected paths
+func newUnprotectedGuard() *DeletionGuard {
+	return &DeletionGuard{fs: OSFileSystem{}, log: defaultLogger()}
+}
+
+func TestHomeDirectoryShouldNotCoverItsSiblings(t *testing.T) {
+	// given
+	base := t.TempDir()
+	home := makeDir(base, "root", t)
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	// then
+	if !isHomeDirectory(normalizePath(home)) || !isHomeDirectory(normalizePath(base)) {
+		t.Error("The home and the folders containing it should be refused.")
+	}
+	if isHomeDirectory(normalizePath(filepath.Join(base, "builds"))) {
+		t.Error("A folder next to the home is not a home.")
+	}
+	if runtime.GOOS == "linux" && !isHomeDirectory(normalizePath("/home/someone")) {
+		t.Error("The home of another user should be refused.")
+	}
+}
@@ -0,0 +1,186 @@
+// creates the config.json and FilterPlugin.ini of a new setup, from a few answers about the plugin.
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+// where the docs go inside the plugin when the answers don't say otherwise
+const defaultDocsFolderInPlugin = "/Docs/"
+
+// what the new config and ini are made from
+type ScaffoldAnswers struct {
+	PluginPath          string
+	EngineBaseDirectory string
+	BuildScriptPath     string
+	OutputBaseDirectory string
+	// the pdf to ship with the plugin, none if empty
+	DocsPath string
+	// where the pdf goes inside the released plugin, like /Docs/MyPlugin.pdf
+	DocsPathInPlugin string
+}
+
+/*
+Suggests the answers for the plugin, and lists the engines found by the launcher or in the suggested engine base directory.
+The engine base directory is only suggested if the engines found are all UE_<version> folders next to each other.
+*/
+func SuggestScaffold(pluginPath string) (ScaffoldAnswers, []model.EngineInstall) {
+	answers := ScaffoldAnswers{
+		PluginPath:          pluginPath,
+		BuildScriptPath:     defaultBuildScriptPath(),
+		OutputBaseDirectory: suggestOutputDirectory(pluginPath),
+	}
+
+	probe := &model.Config{BuildScriptPath: answers.BuildScriptPath, EngineBaseDirectory: defaultEngineBaseDirectory()}
+	engines := NewEngineResolver(probe).FindEngines()
+	answers.EngineBaseDirectory = commonEngineBaseDirectory(engines)
+	return answers, engines
+}
+
+// the docs keep their file name inside the plugin, unless told otherwise
+func SuggestDocsPathInPlugin(docsPath string) string {
+	return defaultDocsFolderInPlugin + filepath.Base(docsPath)
+}
+
+/*
+Writes config.json with its JSON Schema next to it and, if the answers include docs, FilterPlugin.ini, creating the output directory too.
+Existing files are only replaced if overwrite is set. The config is written as json, so paths are escaped properly.
+*/
+func WriteScaffold(answers ScaffoldAnswers, configPath string, iniPath string, overwrite bool) error {
+	if err := validateScaffoldAnswers(answers); err != nil {
+		return err
+	}
+	written := []string{configPath}
+	if answers.DocsPath != "" {
+		written = append(written, iniPath)
+	}
+	for _, path := range written {
+		if IsPathExist(path) && !overwrite {
+			return fmt.Errorf("%s already exists, use --force to replace it", path)
+		}
+	}
+
+	if err := os.MkdirAll(answers.OutputBaseDirectory, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	config := model.Config{
+		Schema:              "./" + model.ConfigSchemaFile,
+		EngineBaseDirectory: answers.EngineBaseDirectory,
+		BuildScriptPath:     answers.BuildScriptPath,
+		OutputBaseDirectory: answers.OutputBaseDirectory,
+		PluginPath:          answers.PluginPath,
+		DocsPath:            answers.DocsPath,
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(configPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+
+	// generated, so it's always replaced with the one of the running version
+	schema, err := ConfigSchema()
+	if err != nil {
+		return err
+	}
+	schemaPath := filepath.Join(filepath.Dir(configPath), model.ConfigSchemaFile)
+	if err := os.WriteFile(schemaPath, schema, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", schemaPath, err)
+	}
+
+	if answers.DocsPath == "" {
+		return nil
+	}
+	if err := os.WriteFile(iniPath, []byte(filterPluginIni(answers.DocsPathInPlugin)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", iniPath, err)
+	}
+	return nil
+}
+
+func validateScaffoldAnswers(answers ScaffoldAnswers) error {
+	if filepath.Ext(answers.PluginPath) != ".uplugin" || !IsFile(answers.PluginPath) {
+		return fmt.Errorf("plugin not found, it must be an existing .uplugin file: %s", answers.PluginPath)
+	}
+	if answers.OutputBaseDirectory == "" {
+		return errors.New("the output directory is required")
+	}
+	if isWithinPath(answers.OutputBaseDirectory, filepath.Dir(answers.PluginPath)) {
+		return fmt.Errorf("the output directory can't be inside the plugin: %s", answers.OutputBaseDirectory)
+	}
+	if answers.BuildScriptPath == "" {
+		return errors.New("the build script path is required")
+	}
+	if answers.DocsPath != "" {
+		if !IsFile(answers.DocsPath) {
+			return fmt.Errorf("docs not found: %s", answers.DocsPath)
+		}
+		if !strings.HasPrefix(answers.DocsPathInPlugin, "/") {
+			return fmt.Errorf("the path of the docs inside the plugin must start with /, like /Docs/MyPlugin.pdf: %s", answers.DocsPathInPlugin)
+		}
+	}
+	return nil
+}
+
+// the ini the release copies into Config, its second line is where the docs go inside the plugin
+func filterPluginIni(docsPathInPlugin string) string {
+	return "[FilterPlugin]\n" + docsPathInPlugin + "\n"
+}
+
+// a Releases folder next to the project of the plugin, or next to the plugin if it's not in a project
+func suggestOutputDirectory(pluginPath string) string {
+	pluginDir := filepath.Dir(pluginPath)
+	pluginName := createPluginName(pluginPath)
+
+	pluginsDir := filepath.Dir(pluginDir)
+	if strings.EqualFold(filepath.Base(pluginsDir), "Plugins") {
+		projectDir := filepath.Dir(pluginsDir)
+		return filepath.Join(filepath.Dir(projectDir), "Releases", pluginName)
+	}
+	return filepath.Join(filepath.Dir(pluginDir), pluginName+"_Releases")
+}
+
+// the parent of the engines, if they are all UE_<version> folders in the same one
+func commonEngineBaseDirectory(engines []model.EngineInstall) string {
+	parent := ""
+	for _, engine := range engines {
+		dir := filepath.Dir(engine.Root)
+		if filepath.Base(engine.Root) != "UE_"+engine.Version || (parent != "" && dir != parent) {
+			return ""
+		}
+		parent = dir
+	}
+	return parent
+}
+
+// where the Epic Launcher installs the engines by default
+func defaultEngineBaseDirectory() string {
+	switch runtime.GOOS {
+	case "windows":
+		programFiles := os.Getenv("ProgramFiles")
+		if programFiles == "" {
+			programFiles = `C:\Program Files`
+		}
+		return filepath.Join(programFiles, "Epic Games")
+	case "darwin":
+		return filepath.Join("/Users", "Shared", "Epic Games")
+	default:
+		return ""
+	}
+}
+
+func defaultBuildScriptPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join("Engine", "Build", "BatchFiles", "RunUAT.bat")
+	}
+	return filepath.Join("Engine", "Build", "BatchFiles", "RunUAT.sh")
+}
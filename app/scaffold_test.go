@@ -0,0 +1,98 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestSuggestOutputDirectoryShouldPutReleasesNextToTheProject(t *testing.T) {
+	inProject := filepath.Join("work", "MyProject", "Plugins", "MyPlugin", "MyPlugin.uplugin")
+	if actual := suggestOutputDirectory(inProject); actual != filepath.Join("work", "Releases", "MyPlugin") {
+		t.Errorf("A plugin in a project should be released next to the project, got %q", actual)
+	}
+
+	standalone := filepath.Join("work", "MyPlugin", "MyPlugin.uplugin")
+	if actual := suggestOutputDirectory(standalone); actual != filepath.Join("work", "MyPlugin_Releases") {
+		t.Errorf("A standalone plugin should be released next to itself, got %q", actual)
+	}
+}
+
+func TestCommonEngineBaseDirectoryShouldOnlyAcceptConventionalFolders(t *testing.T) {
+	epic := filepath.Join("C:", "Epic Games")
+	conventional := []model.EngineInstall{
+		{Version: "5.3", Root: filepath.Join(epic, "UE_5.3")},
+		{Version: "5.4", Root: filepath.Join(epic, "UE_5.4")},
+	}
+	if actual := commonEngineBaseDirectory(conventional); actual != epic {
+		t.Errorf("Expected %q, got %q", epic, actual)
+	}
+
+	mixed := append(conventional, model.EngineInstall{Version: "5.5", Root: filepath.Join("D:", "src", "UE5")})
+	if actual := commonEngineBaseDirectory(mixed); actual != "" {
+		t.Errorf("Engines outside the convention should not get a base directory, got %q", actual)
+	}
+}
+
+func TestWriteScaffoldShouldWriteALoadableConfigAndIni(t *testing.T) {
+	// given
+	base := t.TempDir()
+	uplugin := writeDescriptor(makeDir(base, "MyPlugin", t), testDescriptor, t)
+	docs := makeFile(base, "Manual.pdf", t)
+	answers := ScaffoldAnswers{
+		PluginPath:          uplugin,
+		EngineBaseDirectory: filepath.Join(base, "Engines"),
+		BuildScriptPath:     filepath.Join("Engine", "Build", "BatchFiles", "RunUAT.bat"),
+		OutputBaseDirectory: filepath.Join(base, "Releases", "MyPlugin"),
+		DocsPath:            docs,
+		DocsPathInPlugin:    SuggestDocsPathInPlugin(docs),
+	}
+	configPath := filepath.Join(base, model.ConfigFile)
+	iniPath := filepath.Join(base, model.PluginConfigurationIniFileName)
+
+	// when
+	err := WriteScaffold(answers, configPath, iniPath, false)
+
+	// then
+	if err != nil {
+		t.Fatalf("The scaffold should have been written: %v", err)
+	}
+	config, err := CreateConfig(configPath)
+	if err != nil {
+		t.Fatalf("The written config should load: %v", err)
+	}
+	if config.PluginPath != uplugin || config.DocsPath != docs || config.OutputBaseDirectory != answers.OutputBaseDirectory {
+		t.Errorf("The config should hold the answers, got %+v", config)
+	}
+	if !IsPathExist(answers.OutputBaseDirectory) {
+		t.Error("The output directory should have been created.")
+	}
+	if config.Schema != "./"+model.ConfigSchemaFile || !IsFile(filepath.Join(base, model.ConfigSchemaFile)) {
+		t.Errorf("The schema should be written next to the config and referred to, got %q", config.Schema)
+	}
+
+	ini, _ := os.ReadFile(iniPath)
+	if string(ini) != "[FilterPlugin]\n/Docs/Manual.pdf\n" {
+		t.Errorf("The ini should have the docs path on its second line, got %q", ini)
+	}
+
+	if err := WriteScaffold(answers, configPath, iniPath, false); err == nil {
+		t.Error("An existing config should not be replaced without overwrite.")
+	}
+}
+
+func TestWriteScaffoldShouldRefuseOutputInsideThePlugin(t *testing.T) {
+	base := t.TempDir()
+	pluginDir := makeDir(base, "MyPlugin", t)
+	answers := ScaffoldAnswers{
+		PluginPath:          writeDescriptor(pluginDir, testDescriptor, t),
+		BuildScriptPath:     "RunUAT.bat",
+		OutputBaseDirectory: filepath.Join(pluginDir, "Releases"),
+	}
+
+	if err := WriteScaffold(answers, filepath.Join(base, model.ConfigFile), filepath.Join(base, "FilterPlugin.ini"), false); err == nil {
+		t.Error("An output directory inside the plugin should be refused.")
+	}
+}
@@ -0,0 +1,156 @@
+// generates the JSON Schema of config.json from the config model, so editors can complete and check the config.
+package app
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+// the fields config.json can't do without, the profiles may still leave them out
+var requiredConfigFields = []string{"buildScriptPath", "outputBaseDirectory", "pluginPath"}
+
+// the fields whose keys are MAJOR.MINOR engine versions
+var versionKeyedFields = map[string]bool{"engines": true, "versions": true}
+
+// what the editors show for the fields, by json name, the same for a field wherever it appears
+var configFieldDescriptions = map[string]string{
+	"$schema":               "The JSON Schema of this file, e.g. ./" + model.ConfigSchemaFile + ".",
+	"engineBaseDirectory":   "The folder that contains the UE_5.1, UE_5.2 etc folders.",
+	"buildScriptPath":       "The path to the RunUAT file within the engine folder.",
+	"outputBaseDirectory":   "The folder the releases are written to.",
+	"pluginPath":            "The path to the .uplugin file to be built.",
+	"docsPath":              "The pdf documentation shipped with the plugin, FilterPlugin.ini tells where it goes.",
+	"engines":               "The engine root or build script path by version, for engines outside engineBaseDirectory.",
+	"launcherInstalledPath": "The Epic Launcher's LauncherInstalled.dat, if not in its default location.",
+	"outputNameTemplate":    "Go template naming the release folders and archives, e.g. {{.PluginName}}_{{.PluginVersion}}_UE{{.EngineVersion}}.",
+	"targetPlatforms":       "The platforms to build for, e.g. Win64 and Linux.",
+	"extraUatArgs":          "Extra BuildPlugin arguments by engine version constraint, e.g. {\">=5.3\": [\"-StrictIncludes\"]}, an argument prefixed with ! is removed instead.",
+	"retry":                 "How many times a failed build is attempted, and which failures are worth another attempt.",
+	"maxAttempts":           "The number of attempts of a build, including the first one.",
+	"backoffSeconds":        "The wait before the next attempt.",
+	"retryablePatterns":     "Regular expressions matching the UAT output of failures worth another attempt.",
+	"versions":              "Settings of a single engine version, overriding the global ones.",
+	"stages":                "The steps of a release in order, from build to publish, with their options.",
+	"name":                  "The name of the stage: " + strings.Join(defaultStageNames, ", ") + ", or one given by the embedding program.",
+	"options":               "The options of the stage, e.g. {\"folders\": [\"Binaries\"]} for clean.",
+	"hooks":                 "Commands run before or after parts of the batch, with its context in UPR_ environment variables.",
+	"preBatch":              "Run before the first version.",
+	"preVersion":            "Run before every version.",
+	"postBuild":             "Run after a version is built.",
+	"postArchive":           "Run after a version is archived.",
+	"postBatch":             "Run after the last version.",
+	"onFailure":             "Run when a version fails.",
+	"retention":             "Which old releases clean removes, by count per engine version and by age, the ones tagged as published are always kept.",
+	"keepLast":              "The number of releases kept per engine version, however old they are.",
+	"maxAgeDays":            "Releases older than this are removed, but the last keepLast, or the newest one, of every engine version.",
+	"removeLastOfVersion":   "Let maxAgeDays remove even the newest release of an engine version, when keepLast isn't set.",
+	"afterBatch":            "Apply the retention after every successful batch too.",
+	"profiles":              "Named overrides of the settings above, selected with --profile.",
+}
+
+/*
+Generates the JSON Schema of config.json. Unknown fields are not allowed, just like when the config is loaded,
+and a profile may set any field but the profiles themselves.
+*/
+func ConfigSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(model.Config{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "unreal-plugin-release " + model.ConfigFile
+	schema["required"] = requiredConfigFields
+
+	properties := schema["properties"].(map[string]any)
+	profile := typeSchema(reflect.TypeOf(model.Config{}))
+	profileProperties := profile["properties"].(map[string]any)
+	delete(profileProperties, "$schema")
+	delete(profileProperties, "profiles")
+
+	allowPlatformValues(properties)
+	allowPlatformValues(profileProperties)
+
+	profiles := properties["profiles"].(map[string]any)
+	profiles["additionalProperties"] = map[string]any{"$ref": "#/$defs/profile"}
+	schema["$defs"] = map[string]any{"profile": profile}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// the schema of a value of the given type, as the json package reads it
+func typeSchema(t reflect.Type) map[string]any {
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		for i := 0; i < t.NumField(); i++ {
+			name := jsonFieldName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			property := typeSchema(t.Field(i).Type)
+			if description, ok := configFieldDescriptions[name]; ok {
+				property["description"] = description
+			}
+			if versionKeyedFields[name] {
+				property["propertyNames"] = map[string]any{"pattern": engineVersionExpression.String()}
+			}
+			properties[name] = property
+		}
+		return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	default:
+		return map[string]any{}
+	}
+}
+
+// the text fields, the engines and the hooks can also be given per OS, see selectPlatformValues
+func allowPlatformValues(properties map[string]any) {
+	for _, name := range platformTextFields() {
+		properties[name] = platformValueSchema(properties[name].(map[string]any))
+	}
+	engines := properties["engines"].(map[string]any)
+	engines["additionalProperties"] = platformValueSchema(engines["additionalProperties"].(map[string]any))
+	hooks := properties["hooks"].(map[string]any)["properties"].(map[string]any)
+	for name, hook := range hooks {
+		hooks[name] = platformValueSchema(hook.(map[string]any))
+	}
+}
+
+// text, or an object with the text for every OS
+func platformValueSchema(text map[string]any) map[string]any {
+	platforms := map[string]any{}
+	for _, platform := range platformKeys {
+		platforms[platform] = map[string]any{"type": "string"}
+	}
+	schema := map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "object", "properties": platforms, "additionalProperties": false},
+		},
+	}
+	if description, ok := text["description"]; ok {
+		schema["description"] = description
+	}
+	return schema
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConfigSchemaShouldDescribeEveryField(t *testing.T) {
+	// when
+	data, err := ConfigSchema()
+
+	// then
+	if err != nil {
+		t.Fatalf("The schema should be generated: %v", err)
+	}
+	var schema struct {
+		Properties           map[string]map[string]any `json:"properties"`
+		AdditionalProperties bool                      `json:"additionalProperties"`
+		Required             []string                  `json:"required"`
+		Defs                 map[string]any            `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("The schema should be valid json: %v", err)
+	}
+	if schema.AdditionalProperties || len(schema.Required) == 0 || schema.Defs["profile"] == nil {
+		t.Errorf("The schema should reject unknown fields, require the paths and define the profiles, got %s", data)
+	}
+	for _, name := range configFieldNames() {
+		if _, ok := configFieldDescriptions[name]; !ok {
+			t.Errorf("The field %s should have a description", name)
+		}
+	}
+	if schema.Properties["docsPath"]["description"] == nil {
+		t.Errorf("The fields should have their description, got %v", schema.Properties["docsPath"])
+	}
+}
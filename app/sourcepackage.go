@@ -0,0 +1,55 @@
+// packages the plugin source without building it, for submissions that only need the source.
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"unreal-plugin-release/model"
+)
+
+/*
+Copies the plugin directory into the staging directory instead of building it, leaving out what the build would remove.
+With verifyBuild, the plugin is built into a temporary directory first, only to prove that it compiles.
+*/
+func (pb *PluginBuilder) packageSourceForEngineVersion(ctx context.Context, version, stagingDir string, platforms []string, verifyBuild bool) error {
+	if verifyBuild {
+		if err := pb.verifyBuild(ctx, version, filepath.Base(stagingDir), platforms); err != nil {
+			return err
+		}
+	} else {
+		pb.report.Versions = append(pb.report.Versions, model.VersionReport{Version: version, OutputDir: stagingDir, Succeeded: true})
+	}
+	pb.report.Versions[len(pb.report.Versions)-1].PackageOnly = true
+
+	pb.log.Info("packaging source", "version", version, "path", stagingDir)
+
+	if err := createOwnedDirectory(pb.fs, filepath.Dir(stagingDir)); err != nil {
+		return fmt.Errorf("failed to create staging folder: %w", err)
+	}
+
+	if err := copyDirectory(pb.fs, filepath.Dir(pb.config.PluginPath), stagingDir, pb.getUnneededFolders()); err != nil {
+		return fmt.Errorf("failed to copy plugin source: %w", err)
+	}
+	return nil
+}
+
+// builds the plugin into a temporary directory that is removed right after
+func (pb *PluginBuilder) verifyBuild(ctx context.Context, version, outputName string, platforms []string) error {
+	tempDir, err := pb.fs.MkdirTemp("", "unreal-plugin-release-verify-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary build folder: %w", err)
+	}
+	if err := markOwned(pb.fs, tempDir, ownedDirectoryEntry); err != nil {
+		pb.fs.Remove(tempDir)
+		return fmt.Errorf("failed to mark temporary build folder: %w", err)
+	}
+	defer pb.guard.Remove(tempDir)
+
+	if err := pb.runBuildForEngineVersion(ctx, version, filepath.Join(tempDir, outputName), pb.config.PluginPath, platforms); err != nil {
+		return fmt.Errorf("build verification failed: %w", err)
+	}
+	pb.log.Info("build verified", "version", version)
+	return nil
+}
@@ -0,0 +1,191 @@
+// the release pipeline: the ordered stages every version goes through, from building it to publishing it.
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+const (
+	buildStageName    = "build"
+	cleanStageName    = "clean"
+	stampStageName    = "stamp"
+	docsStageName     = "docs"
+	archiveStageName  = "archive"
+	checksumStageName = "checksum"
+	publishStageName  = "publish"
+)
+
+// the pipeline used when the config doesn't list the stages
+var defaultStageNames = []string{buildStageName, cleanStageName, stampStageName, docsStageName, archiveStageName, checksumStageName, publishStageName}
+
+// a version's release, as it moves through the stages
+type Release struct {
+	Version    string
+	StagingDir string
+	OutputDir  string
+	Platforms  []string
+	CmdInput   model.CmdInput
+	ExecPath   string
+	// the verified exact version of the engine, nil if the engine has no Build.version
+	EngineBuild *model.EngineBuildVersion
+}
+
+// a step of the release pipeline, working on the release in its staging directory
+type Stage interface {
+	Name() string
+	Run(ctx context.Context, release *Release) error
+}
+
+// creates a stage from the options given to it in the config
+type StageFactory func(pb *PluginBuilder, options json.RawMessage) (Stage, error)
+
+// the built-in stages, never modified, the ones of an embedding program are given to its builder through BuilderOptions.Stages
+var builtinStages = map[string]StageFactory{
+	buildStageName:    newStageWithoutOptions(buildStageName, (*PluginBuilder).buildRelease),
+	cleanStageName:    newCleanStage,
+	stampStageName:    newStageWithoutOptions(stampStageName, (*PluginBuilder).stampRelease),
+	docsStageName:     newStageWithoutOptions(docsStageName, (*PluginBuilder).documentRelease),
+	archiveStageName:  newStageWithoutOptions(archiveStageName, (*PluginBuilder).archiveRelease),
+	checksumStageName: newStageWithoutOptions(checksumStageName, (*PluginBuilder).checksumRelease),
+	publishStageName:  newStageWithoutOptions(publishStageName, (*PluginBuilder).publishStagedRelease),
+}
+
+/*
+Checks that the stages of the config exist, among the built-in ones and the given ones, their options are valid,
+and the release is built first and published in the end.
+*/
+func ValidateStages(config *model.Config, stages map[string]StageFactory) error {
+	_, err := NewPluginBuilderWithOptions(config, nil, BuilderOptions{Stages: stages}).createStages()
+	return err
+}
+
+// a stage running a single function
+type funcStage struct {
+	name string
+	run  func(ctx context.Context, release *Release) error
+}
+
+func (s funcStage) Name() string {
+	return s.name
+}
+
+func (s funcStage) Run(ctx context.Context, release *Release) error {
+	return s.run(ctx, release)
+}
+
+func newStageWithoutOptions(name string, run func(pb *PluginBuilder, ctx context.Context, release *Release) error) StageFactory {
+	return func(pb *PluginBuilder, options json.RawMessage) (Stage, error) {
+		if err := decodeStageOptions(options, &struct{}{}); err != nil {
+			return nil, err
+		}
+		return funcStage{name: name, run: func(ctx context.Context, release *Release) error { return run(pb, ctx, release) }}, nil
+	}
+}
+
+type cleanStageOptions struct {
+	Folders []string `json:"folders"`
+}
+
+// removes the folders a release doesn't need, the build's intermediate files by default
+func newCleanStage(pb *PluginBuilder, options json.RawMessage) (Stage, error) {
+	cleanOptions := cleanStageOptions{Folders: pb.getUnneededFolders()}
+	if err := decodeStageOptions(options, &cleanOptions); err != nil {
+		return nil, err
+	}
+
+	return funcStage{name: cleanStageName, run: func(ctx context.Context, release *Release) error {
+		for _, folder := range cleanOptions.Folders {
+			deleteSubFolder(pb.guard, release.StagingDir, folder)
+		}
+		return nil
+	}}, nil
+}
+
+// options are strict, so a misspelled option is reported instead of silently ignored
+func decodeStageOptions(options json.RawMessage, target any) error {
+	if len(options) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(options))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
+
+// the stages listed in the config, or the default ones, in their order
+func (pb *PluginBuilder) createStages() ([]Stage, error) {
+	stageConfigs := pb.config.Stages
+	if len(stageConfigs) == 0 {
+		for _, name := range defaultStageNames {
+			stageConfigs = append(stageConfigs, model.StageConfig{Name: name})
+		}
+	}
+
+	stages := make([]Stage, 0, len(stageConfigs))
+	seen := map[string]bool{}
+	for _, stageConfig := range stageConfigs {
+		factory, ok := pb.stageFactories[stageConfig.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown stage %q, available stages: %s", stageConfig.Name, strings.Join(sortedKeys(pb.stageFactories), ", "))
+		}
+		if seen[stageConfig.Name] {
+			return nil, fmt.Errorf("stage %q is listed more than once", stageConfig.Name)
+		}
+		seen[stageConfig.Name] = true
+
+		stage, err := factory(pb, stageConfig.Options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid options of stage %q: %w", stageConfig.Name, err)
+		}
+		stages = append(stages, stage)
+	}
+
+	if stages[0].Name() != buildStageName {
+		return nil, errors.New("the first stage must be " + buildStageName + ", the others work on its output")
+	}
+	if !seen[publishStageName] {
+		return nil, errors.New("the stages must include " + publishStageName + ", otherwise the release never leaves the staging area")
+	}
+	if last := stages[len(stages)-1].Name(); last != publishStageName {
+		return nil, fmt.Errorf("%s must be the last stage, %s after it would find the staging area already moved", publishStageName, last)
+	}
+	return stages, nil
+}
+
+// runs the stages in order, stopping at the first failure or when the context is cancelled
+func (pb *PluginBuilder) runStages(ctx context.Context, stages []Stage, release *Release) error {
+	for _, stage := range stages {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pb.emit(model.StageStarted{Version: release.Version, Stage: stage.Name()})
+		started := pb.now()
+		err := stage.Run(ctx, release)
+		pb.emit(model.StageFinished{Version: release.Version, Stage: stage.Name(), Duration: pb.now().Sub(started), Err: err})
+		if err != nil {
+			return fmt.Errorf("%s stage failed: %w", stage.Name(), err)
+		}
+		pb.recordStageCompleted(release.Version, stage.Name())
+
+		if hook, ok := postStageHooks[stage.Name()]; ok {
+			pb.runPostHook(ctx, hook, release, hookStatusRunning)
+		}
+	}
+	return nil
+}
+
+func stageNames(stages []Stage) []string {
+	names := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		names = append(names, stage.Name())
+	}
+	return names
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestCreateStagesShouldDefaultToFullPipeline(t *testing.T) {
+	// given
+	underTest := NewPluginBuilder(&model.Config{}, FakeExecutor{})
+
+	// when
+	stages, err := underTest.createStages()
+
+	// then
+	if err != nil {
+		t.Fatalf("The default stages should be valid: %v", err)
+	}
+	if !slices.Equal(stageNames(stages), defaultStageNames) {
+		t.Errorf("Expected %v, actual %v", defaultStageNames, stageNames(stages))
+	}
+}
+
+func TestCreateStagesShouldRejectInvalidPipelines(t *testing.T) {
+	cases := map[string][]model.StageConfig{
+		"unknown stage":   {{Name: "build"}, {Name: "upload"}, {Name: "publish"}},
+		"duplicate stage": {{Name: "build"}, {Name: "clean"}, {Name: "clean"}, {Name: "publish"}},
+		"build not first": {{Name: "clean"}, {Name: "build"}, {Name: "publish"}},
+		"no publish":      {{Name: "build"}, {Name: "archive"}},
+		"after publish":   {{Name: "build"}, {Name: "publish"}, {Name: "checksum"}},
+		"unknown option":  {{Name: "build"}, {Name: "clean", Options: json.RawMessage(`{"folder": ["Saved"]}`)}, {Name: "publish"}},
+		"stage options":   {{Name: "build", Options: json.RawMessage(`{"fast": true}`)}, {Name: "publish"}},
+	}
+
+	for name, stageConfigs := range cases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			underTest := NewPluginBuilder(&model.Config{Stages: stageConfigs}, FakeExecutor{})
+
+			// when
+			_, err := underTest.createStages()
+
+			// then
+			if err == nil {
+				t.Error("The pipeline should have been rejected.")
+			}
+		})
+	}
+}
+
+func TestCleanStageShouldRemoveConfiguredFolders(t *testing.T) {
+	// given
+	base := t.TempDir()
+	stagingDir := filepath.Join(base, "MyPlugin_5.4")
+	createOwnedDirectory(OSFileSystem{}, stagingDir)
+	makeDir(stagingDir, "Saved", t)
+	makeDir(stagingDir, "Binaries", t)
+	underTest := NewPluginBuilder(&model.Config{}, FakeExecutor{})
+	stage, err := newCleanStage(underTest, json.RawMessage(`{"folders": ["Saved"]}`))
+	if err != nil {
+		t.Fatalf("Valid options were rejected: %v", err)
+	}
+
+	// when
+	err = stage.Run(context.Background(), &Release{Version: "5.4", StagingDir: stagingDir})
+
+	// then
+	if err != nil {
+		t.Fatalf("Cleaning should not fail: %v", err)
+	}
+	if IsPathExist(filepath.Join(stagingDir, "Saved")) {
+		t.Error("The configured folder should have been removed.")
+	}
+	if !IsPathExist(filepath.Join(stagingDir, "Binaries")) {
+		t.Error("Only the configured folders should be removed.")
+	}
+}
+
+func TestGivenStageShouldRunInConfiguredOrder(t *testing.T) {
+	// given
+	var ran []string
+	extra := map[string]StageFactory{"validate": func(pb *PluginBuilder, options json.RawMessage) (Stage, error) {
+		return funcStage{name: "validate", run: func(ctx context.Context, release *Release) error {
+			ran = append(ran, "validate "+release.Version)
+			return errors.New("descriptor is invalid")
+		}}, nil
+	}}
+	config := model.Config{Stages: []model.StageConfig{{Name: "build"}, {Name: "validate"}, {Name: "publish"}}}
+	underTest := NewPluginBuilderWithOptions(&config, FakeExecutor{}, BuilderOptions{Stages: extra})
+	stages, err := underTest.createStages()
+	if err != nil {
+		t.Fatalf("The given stage should be accepted: %v", err)
+	}
+	if err := ValidateStages(&config, nil); err == nil {
+		t.Error("The stage should be unknown to the other builders.")
+	}
+
+	// when
+	err = underTest.runStages(context.Background(), stages[1:], &Release{Version: "5.4"})
+
+	// then
+	if !slices.Equal(ran, []string{"validate 5.4"}) {
+		t.Errorf("The given stage should have run, ran %v", ran)
+	}
+	if err == nil || !strings.Contains(err.Error(), "validate stage failed") {
+		t.Errorf("A failing stage should stop the pipeline with its name, got %v", err)
+	}
+}
+
+func TestChecksumStageShouldRequireArchive(t *testing.T) {
+	// given
+	underTest := NewPluginBuilder(&model.Config{}, FakeExecutor{})
+
+	// when
+	err := underTest.checksumRelease(context.Background(), &Release{Version: "5.4", StagingDir: filepath.Join(t.TempDir(), "MyPlugin_5.4")})
+
+	// then
+	if err == nil {
+		t.Error("A checksum without an archive should fail.")
+	}
+}
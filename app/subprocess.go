@@ -0,0 +1,36 @@
+// runs the executor's commands so that cancelling the batch stops them too.
+package app
+
+import (
+	"context"
+	"os/exec"
+)
+
+/*
+Runs the command until it finishes or the context is cancelled, in which case the process is killed
+and the context's error is returned.
+*/
+func runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cmd.Process.Kill()
+		case <-finished:
+		}
+	}()
+
+	err := cmd.Wait()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
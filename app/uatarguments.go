@@ -0,0 +1,89 @@
+// assembles the BuildPlugin arguments that differ between engine versions.
+package app
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// arguments that are added for every engine version matching the constraint
+type versionArguments struct {
+	constraint string
+	args       []string
+}
+
+// the known differences between UE4 and UE5, applied before the ones in the config.
+// UE4's BuildPlugin needs -Rocket for an installed engine, UE5 no longer does.
+var defaultUatArguments = []versionArguments{
+	{"<5.0", []string{"-Rocket"}},
+}
+
+// an argument of extraUatArgs starting with it removes the argument instead of adding it, like !-Rocket
+const removeUatArgumentPrefix = "!"
+
+/*
+Resolves every BuildPlugin argument for the version: the built-in defaults, then the arguments of every matching constraint
+of the config's extraUatArgs, then the target platforms.
+An argument of the config prefixed with ! removes the argument, with or without a value, after everything else was added,
+so a removal wins over an addition whichever constraints they are under, and a default can be dropped or replaced.
+*/
+func (pb *PluginBuilder) uatArgumentsFor(version string, platforms []string) ([]string, error) {
+	var args []string
+	for _, entry := range defaultUatArguments {
+		matched, err := matchesVersionConstraint(version, entry.constraint)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			args = appendMissing(args, entry.args)
+		}
+	}
+
+	var removals []string
+	for _, constraint := range sortedConstraints(pb.config.ExtraUatArgs) {
+		matched, err := matchesVersionConstraint(version, constraint)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+		for _, arg := range pb.config.ExtraUatArgs[constraint] {
+			if removed, ok := strings.CutPrefix(arg, removeUatArgumentPrefix); ok {
+				removals = append(removals, removed)
+			} else {
+				args = appendMissing(args, []string{arg})
+			}
+		}
+	}
+
+	return appendMissing(removeUatArguments(args, removals), createPlatformArguments(platforms)), nil
+}
+
+func sortedConstraints(extraArgs map[string][]string) []string {
+	constraints := make([]string, 0, len(extraArgs))
+	for constraint := range extraArgs {
+		constraints = append(constraints, constraint)
+	}
+	sort.Strings(constraints)
+	return constraints
+}
+
+// removes the arguments, with or without their =value
+func removeUatArguments(args []string, removals []string) []string {
+	return slices.DeleteFunc(args, func(arg string) bool {
+		return slices.ContainsFunc(removals, func(removed string) bool {
+			return arg == removed || strings.HasPrefix(arg, removed+"=")
+		})
+	})
+}
+
+func appendMissing(args []string, additions []string) []string {
+	for _, arg := range additions {
+		if !slices.Contains(args, arg) {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
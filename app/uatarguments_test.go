@@ -0,0 +1,108 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestUatArgumentsForShouldApplyDefaultsConfigAndPlatforms(t *testing.T) {
+	// given
+	config := model.Config{
+		ExtraUatArgs: map[string][]string{
+			">=5.3": {"-StrictIncludes"},
+			"5.4":   {"-VS2022"},
+		},
+	}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	actual, err := underTest.uatArgumentsFor("5.4", []string{"Win64"})
+
+	// then
+	expected := []string{"-VS2022", "-StrictIncludes", "-TargetPlatforms=Win64", "-NoHostPlatform"}
+	if err != nil || !slices.Equal(expected, actual) {
+		t.Errorf("Expected: %v, Actual: %v (%v)", expected, actual, err)
+	}
+}
+
+func TestUatArgumentsForUE4ShouldUseUE4Defaults(t *testing.T) {
+	// given
+	config := model.Config{ExtraUatArgs: map[string][]string{">=5.3": {"-StrictIncludes"}}}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	actual, _ := underTest.uatArgumentsFor("4.27", nil)
+
+	// then
+	expected := []string{"-Rocket"}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("Expected: %v, Actual: %v", expected, actual)
+	}
+}
+
+func TestUatArgumentsForShouldRemoveArgumentsPrefixedWithExclamationMark(t *testing.T) {
+	// given
+	config := model.Config{ExtraUatArgs: map[string][]string{
+		"*":    {"-Unity=false"},
+		"<5.0": {"!-Rocket", "-VS2022", "!-Unity"},
+	}}
+	underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+	// when
+	actual, _ := underTest.uatArgumentsFor("4.27", nil)
+
+	// then
+	expected := []string{"-VS2022"}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("Expected: %v, Actual: %v", expected, actual)
+	}
+}
+
+func TestRemovedUatArgumentShouldWinWhateverTheOrderOfTheConstraints(t *testing.T) {
+	tests := []map[string][]string{
+		{"5.5": {"-X"}, ">=5.3": {"!-X"}},
+		{"*": {"!-X"}, "5.5": {"-X"}},
+		{">=5.3": {"-X"}, "5.5": {"!-X"}},
+	}
+	for _, extraArgs := range tests {
+		// given
+		config := model.Config{ExtraUatArgs: extraArgs}
+		underTest := NewPluginBuilder(&config, FakeExecutor{})
+
+		// when
+		actual, _ := underTest.uatArgumentsFor("5.5", nil)
+
+		// then
+		if slices.Contains(actual, "-X") {
+			t.Errorf("-X should be removed with %v, actual %v", extraArgs, actual)
+		}
+	}
+}
+
+func TestValidateSettingsShouldRejectInvalidUatArgsConstraint(t *testing.T) {
+	// given
+	config := model.Config{ExtraUatArgs: map[string][]string{"newer than 5.3": {"-StrictIncludes"}}}
+
+	// when
+	problems := ValidateSettings(&config)
+
+	// then
+	if len(problems) != 1 || problems[0].Field != "extraUatArgs" || problems[0].Value != "newer than 5.3" {
+		t.Errorf("The invalid constraint should be reported, got %v", problems)
+	}
+}
+
+func TestEmptyRemovedUatArgumentShouldBeRejected(t *testing.T) {
+	// given
+	config := model.Config{ExtraUatArgs: map[string][]string{"<5.0": {"!"}}}
+
+	// when
+	problems := ValidateSettings(&config)
+
+	// then
+	if len(problems) != 1 || problems[0].Field != "extraUatArgs.<5.0" {
+		t.Errorf("The empty argument should be reported, got %v", problems)
+	}
+}
@@ -0,0 +1,411 @@
+// validates the config field by field, telling what is wrong with each field and how to fix it.
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"unreal-plugin-release/model"
+)
+
+var engineVersionExpression = regexp.MustCompile(`^\d+\.\d+$`)
+
+var platformNameExpression = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// a problem with a field of the config
+type ValidationError struct {
+	// the json name of the field, like pluginPath or engines.5.4
+	Field   string
+	Value   string
+	Problem string
+	Fix     string
+}
+
+func (e ValidationError) Error() string {
+	message := e.Field + ": " + e.Problem
+	if e.Value != "" {
+		message += fmt.Sprintf(" (%q)", e.Value)
+	}
+	if e.Fix != "" {
+		message += ", " + e.Fix
+	}
+	return message
+}
+
+// every problem found in the config, it's only returned when there is at least one
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, problem := range e {
+		messages[i] = problem.Error()
+	}
+	return "invalid config: " + strings.Join(messages, "; ")
+}
+
+/*
+Checks the paths of the config and its settings, returning every problem found, not just the first one.
+The build script path is relative to the engines, so it's only checked for the engines mapped explicitly.
+*/
+func ValidateConfig(config *model.Config) ValidationErrors {
+	var problems ValidationErrors
+	problems = append(problems, validateEngineBaseDirectory(config)...)
+	problems = append(problems, validateOutputBaseDirectory(config)...)
+	problems = append(problems, validatePluginPath(config.PluginPath)...)
+	if config.BuildScriptPath == "" {
+		problems = append(problems, ValidationError{
+			Field:   "buildScriptPath",
+			Problem: "is required",
+			Fix:     "set it to the path of RunUAT within an engine folder, like " + defaultBuildScriptPath(),
+		})
+	}
+	problems = append(problems, validateEngines(config)...)
+	problems = append(problems, ValidateSettings(config)...)
+	return problems
+}
+
+/*
+Checks the settings of the config that don't depend on the file system: the extra UAT arguments, the output name template,
+the target platforms, the per-version settings, the retryable patterns, the retention and the stages.
+*/
+func ValidateSettings(config *model.Config) ValidationErrors {
+	return ValidateSettingsWithStages(config, nil)
+}
+
+/*
+Checks the settings of the config like ValidateSettings, accepting the given stages besides the built-in ones.
+*/
+func ValidateSettingsWithStages(config *model.Config, stages map[string]StageFactory) ValidationErrors {
+	var problems ValidationErrors
+	problems = append(problems, validateTargetPlatforms("targetPlatforms", config.TargetPlatforms)...)
+	for _, version := range sortedKeys(config.Versions) {
+		if !engineVersionExpression.MatchString(version) {
+			problems = append(problems, ValidationError{
+				Field:   "versions",
+				Value:   version,
+				Problem: "invalid engine version",
+				Fix:     "use MAJOR.MINOR versions as the keys, e.g. 5.6, the settings of a version are not matched by constraints",
+			})
+		}
+		problems = append(problems, validateTargetPlatforms("versions."+version+".targetPlatforms", config.Versions[version].TargetPlatforms)...)
+	}
+	for _, constraint := range sortedKeys(config.ExtraUatArgs) {
+		if err := ValidateVersionConstraint(constraint); err != nil {
+			problems = append(problems, ValidationError{
+				Field:   "extraUatArgs",
+				Value:   constraint,
+				Problem: err.Error(),
+				Fix:     `use a version constraint as the key, like "*", "5.4" or ">=5.3"`,
+			})
+		}
+		for _, arg := range config.ExtraUatArgs[constraint] {
+			if strings.TrimSpace(strings.TrimPrefix(arg, removeUatArgumentPrefix)) == "" {
+				problems = append(problems, ValidationError{
+					Field:   "extraUatArgs." + constraint,
+					Value:   arg,
+					Problem: "empty argument",
+					Fix:     `add an argument like "-StrictIncludes", or remove one, like "!-Rocket"`,
+				})
+			}
+		}
+	}
+	if err := ValidateOutputNameTemplate(config.OutputNameTemplate); err != nil {
+		problems = append(problems, ValidationError{
+			Field:   "outputNameTemplate",
+			Value:   config.OutputNameTemplate,
+			Problem: err.Error(),
+			Fix:     "use the fields of the plugin and the engine version, like {{.PluginName}}_{{.PluginVersion}}_UE{{.EngineVersion}}",
+		})
+	}
+	problems = append(problems, validateRetryablePatterns("retry", config.Retry)...)
+	for _, version := range sortedKeys(config.Versions) {
+		problems = append(problems, validateRetryablePatterns("versions."+version+".retry", config.Versions[version].Retry)...)
+	}
+	if retention := config.Retention; retention != nil {
+		if retention.KeepLast < 0 || retention.MaxAgeDays < 0 || (retention.AfterBatch && retention.KeepLast == 0 && retention.MaxAgeDays == 0) {
+			problems = append(problems, ValidationError{
+				Field:   "retention",
+				Problem: "needs a positive keepLast or maxAgeDays",
+				Fix:     `keep the last releases of every version, like {"keepLast": 3}, or the recent ones, like {"maxAgeDays": 90}`,
+			})
+		}
+	}
+	if err := ValidateStages(config, stages); err != nil {
+		problems = append(problems, ValidationError{
+			Field:   "stages",
+			Problem: err.Error(),
+			Fix:     "leave stages out for the default pipeline: " + strings.Join(defaultStageNames, ", "),
+		})
+	}
+	return problems
+}
+
+// a platform goes into -TargetPlatforms joined with +, so it's a single name like Win64
+func validateTargetPlatforms(field string, platforms []string) ValidationErrors {
+	var problems ValidationErrors
+	for _, platform := range platforms {
+		if !platformNameExpression.MatchString(platform) {
+			problems = append(problems, ValidationError{
+				Field:   field,
+				Value:   platform,
+				Problem: "invalid platform name",
+				Fix:     "list every platform on its own, like [\"Win64\", \"Linux\"]",
+			})
+		}
+	}
+	return problems
+}
+
+func validateRetryablePatterns(field string, retry *model.RetryConfig) ValidationErrors {
+	if retry == nil {
+		return nil
+	}
+	var problems ValidationErrors
+	for _, pattern := range retry.RetryablePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			problems = append(problems, ValidationError{
+				Field:   field + ".retryablePatterns",
+				Value:   pattern,
+				Problem: "invalid regular expression",
+				Fix:     `escape the special characters, like "Access to the path .* is denied"`,
+			})
+		}
+	}
+	return problems
+}
+
+// the engine base directory can only be left out if the engines are mapped explicitly or installed by the launcher
+func validateEngineBaseDirectory(config *model.Config) ValidationErrors {
+	var problems ValidationErrors
+	if config.LauncherInstalledPath != "" && !IsFile(config.LauncherInstalledPath) {
+		problems = append(problems, ValidationError{
+			Field:   "launcherInstalledPath",
+			Value:   config.LauncherInstalledPath,
+			Problem: "LauncherInstalled.dat not found",
+			Fix:     "remove it to use the default location of the Epic Launcher, or point it to an existing LauncherInstalled.dat",
+		})
+	}
+
+	switch {
+	case config.EngineBaseDirectory == "" && len(NewEngineResolver(config).FindEngines()) == 0:
+		problems = append(problems, ValidationError{
+			Field:   "engineBaseDirectory",
+			Problem: "is empty and no engines were found in engines or by the Epic Launcher",
+			Fix:     "set it to the folder containing the UE_<version> folders, or map the engines in engines",
+		})
+	case config.EngineBaseDirectory != "" && !IsPathExist(config.EngineBaseDirectory):
+		problems = append(problems, ValidationError{
+			Field:   "engineBaseDirectory",
+			Value:   config.EngineBaseDirectory,
+			Problem: "folder not found",
+			Fix:     "set it to the folder containing the UE_<version> folders",
+		})
+	}
+	return problems
+}
+
+func validateOutputBaseDirectory(config *model.Config) ValidationErrors {
+	switch {
+	case config.OutputBaseDirectory == "":
+		return ValidationErrors{{
+			Field:   "outputBaseDirectory",
+			Problem: "is required",
+			Fix:     "set it to an existing folder the releases are written to",
+		}}
+	case !IsPathExist(config.OutputBaseDirectory):
+		return ValidationErrors{{
+			Field:   "outputBaseDirectory",
+			Value:   config.OutputBaseDirectory,
+			Problem: "folder not found",
+			Fix:     "create the folder, or point it to an existing one",
+		}}
+	case config.EngineBaseDirectory != "" && IsPathEqual(config.EngineBaseDirectory, config.OutputBaseDirectory):
+		return ValidationErrors{{
+			Field:   "outputBaseDirectory",
+			Value:   config.OutputBaseDirectory,
+			Problem: "is the same as engineBaseDirectory, the releases would go into the engines",
+			Fix:     "use a separate folder for the releases",
+		}}
+	}
+	return nil
+}
+
+func validatePluginPath(pluginPath string) ValidationErrors {
+	switch {
+	case pluginPath == "":
+		return ValidationErrors{{
+			Field:   "pluginPath",
+			Problem: "is required",
+			Fix:     "set pluginPath in config.json to the path of the .uplugin file",
+		}}
+	case !IsPathExist(pluginPath):
+		return ValidationErrors{{
+			Field:   "pluginPath",
+			Value:   pluginPath,
+			Problem: "plugin file not found",
+			Fix:     "point it to an existing .uplugin file",
+		}}
+	case !IsFile(pluginPath):
+		return ValidationErrors{{
+			Field:   "pluginPath",
+			Value:   pluginPath,
+			Problem: "is a folder",
+			Fix:     "point it to the .uplugin file within the folder",
+		}}
+	}
+	return nil
+}
+
+func validateEngines(config *model.Config) ValidationErrors {
+	var problems ValidationErrors
+	for _, version := range sortedKeys(config.Engines) {
+		location := config.Engines[version]
+		field := "engines." + version
+		if !engineVersionExpression.MatchString(version) {
+			problems = append(problems, ValidationError{
+				Field:   field,
+				Value:   version,
+				Problem: "invalid engine version",
+				Fix:     "use MAJOR.MINOR versions as the keys, e.g. 5.6",
+			})
+			continue
+		}
+
+		if !IsPathExist(location) {
+			problems = append(problems, ValidationError{
+				Field:   field,
+				Value:   location,
+				Problem: "engine not found",
+				Fix:     "point it to the root folder of the engine, or to its build script",
+			})
+			continue
+		}
+
+		if buildScript := NewEngineResolver(config).Resolve(version).BuildScriptPath; !IsFile(buildScript) {
+			problems = append(problems, ValidationError{
+				Field:   field,
+				Value:   buildScript,
+				Problem: "build script not found",
+				Fix:     "check buildScriptPath, or point the engine to its build script directly",
+			})
+		}
+	}
+	return problems
+}
+
+/*
+Turns the errors of decoding the config into the fields they are about: unknown fields, with the known field they are most likely a typo of,
+and values of the wrong type. Other errors are returned as they are.
+*/
+func describeDecodeError(err error, source string) error {
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return ValidationErrors{{
+			Field:   typeError.Field,
+			Value:   typeError.Value,
+			Problem: "has the wrong type",
+			Fix:     "it must be a " + jsonTypeName(typeError.Type),
+		}}
+	}
+
+	// the json package doesn't have a type for it, only this message
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field = strings.Trim(field, `"`)
+		fix := "remove it, see the schema of the config for the known fields"
+		if suggestion := closestFieldName(field); suggestion != "" {
+			fix = fmt.Sprintf("did you mean %s?", suggestion)
+		}
+		return ValidationErrors{{Field: field, Problem: "unknown field in " + source, Fix: fix}}
+	}
+	return fmt.Errorf("invalid %s: %w", source, err)
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "list"
+	default:
+		return "object"
+	}
+}
+
+// the known field names closest to the unknown one, if any is close enough to be a typo
+func closestFieldName(field string) string {
+	best, bestDistance := "", len(field)/2+1
+	for _, name := range configFieldNames() {
+		if distance := editDistance(strings.ToLower(field), strings.ToLower(name)); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+// the json names of every field in the config, including the nested ones
+func configFieldNames() []string {
+	seen := map[string]bool{}
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			name := jsonFieldName(t.Field(i))
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			collect(t.Field(i).Type)
+		}
+	}
+	collect(reflect.TypeOf(model.Config{}))
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// the number of single character edits turning one string into the other
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
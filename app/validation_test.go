@@ -0,0 +1,305 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"unreal-plugin-release/model"
+)
+
+func TestValidPluginLocation(t *testing.T) {
+	// when
+	actual := len(validatePluginPath(makeFile(t.TempDir(), "MyPlugin.uplugin", t))) == 0
+
+	// then
+	if !actual {
+		t.Error("A valid plugin location must not be marked invalid.")
+	}
+}
+
+func TestInvalidPluginLocation(t *testing.T) {
+	// given
+	base := t.TempDir()
+	missingPath := filepath.Join(base, "MissingDirectory", "NoPlugin.uplugin")
+
+	// when
+	actual := len(validatePluginPath(missingPath)) == 0
+
+	// then
+	if actual {
+		t.Error("A missing plugin location must be invalid.")
+	}
+}
+
+func TestMissingPluginLocation(t *testing.T) {
+	// when
+	actual := len(validatePluginPath("")) == 0
+
+	// then
+	if actual {
+		t.Error("An empty plugin location must be invalid.")
+	}
+}
+
+func TestNotFilePluginLocation(t *testing.T) {
+	// given
+	dir := t.TempDir()
+
+	// when
+	actual := len(validatePluginPath(dir)) == 0
+
+	// then
+	if actual {
+		t.Error("A directory as plugin location must be invalid.")
+	}
+}
+
+func TestValidPathsShouldMakeValidConfig(t *testing.T) {
+	// given
+	base := t.TempDir()
+	enginePath := filepath.Join(base, "Engine")
+	outputPath := filepath.Join(base, "Output")
+	if err := os.MkdirAll(enginePath, 0755); err != nil {
+		t.Fatal("Failed to create temp dir for Engine.")
+	}
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		t.Fatal("Failed to create temp dir for Output.")
+	}
+	upluginPath := filepath.Join(enginePath, "MyPlugin.uplugin")
+	f, err := os.Create(upluginPath)
+	if err != nil {
+		t.Fatal("Failed to create uplugin.")
+	}
+	defer f.Close()
+	var config = model.Config{
+		EngineBaseDirectory: enginePath,
+		OutputBaseDirectory: outputPath,
+		BuildScriptPath:     "not empty",
+		PluginPath:          upluginPath,
+	}
+
+	// when
+	actual := len(ValidateConfig(&config)) == 0
+
+	// then
+	if !actual {
+		t.Error("A valid configuration should not fail the validation.")
+	}
+}
+
+func TestMissingEngineBaseDirectoryShouldFailValidation(t *testing.T) {
+	// given
+	base := t.TempDir()
+	enginePath := filepath.Join(base, "Engine")
+	outputPath := filepath.Join(base, "Output")
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		t.Fatal("Failed to create temp dir for Output.")
+	}
+	var config = model.Config{
+		EngineBaseDirectory: enginePath,
+		OutputBaseDirectory: outputPath,
+		BuildScriptPath:     "not empty",
+	}
+
+	// when
+	actual := len(ValidateConfig(&config)) == 0
+
+	// then
+	if actual {
+		t.Error("A missing engine directory should fail the validation.")
+	}
+}
+
+func TestMissingOutputDirectoryShouldFailValidation(t *testing.T) {
+	// given
+	base := t.TempDir()
+	enginePath := filepath.Join(base, "Engine")
+	outputPath := filepath.Join(base, "Output")
+	if err := os.MkdirAll(enginePath, 0755); err != nil {
+		t.Fatal("Failed to create temp dir for Engine.")
+	}
+	var config = model.Config{
+		EngineBaseDirectory: enginePath,
+		OutputBaseDirectory: outputPath,
+		BuildScriptPath:     "not empty",
+	}
+
+	// when
+	actual := len(ValidateConfig(&config)) == 0
+
+	// then
+	if actual {
+		t.Error("A missing output directory should fail the validation.")
+	}
+}
+
+func TestMissingBuildScriptPathShouldFailValidation(t *testing.T) {
+	// given
+	base := t.TempDir()
+	enginePath := filepath.Join(base, "Engine")
+	outputPath := filepath.Join(base, "Output")
+	if err := os.MkdirAll(enginePath, 0755); err != nil {
+		t.Fatal("Failed to create temp dir for Engine.")
+	}
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		t.Fatal("Failed to create temp dir for Output.")
+	}
+	var config = model.Config{
+		EngineBaseDirectory: enginePath,
+		OutputBaseDirectory: outputPath,
+		BuildScriptPath:     "",
+	}
+
+	// when
+	actual := len(ValidateConfig(&config)) == 0
+
+	// then
+	if actual {
+		t.Error("A missing build script path should fail the validation.")
+	}
+}
+
+func TestMappedEnginesShouldReplaceEngineBaseDirectory(t *testing.T) {
+	// given
+	base := t.TempDir()
+	outputPath := filepath.Join(base, "Output")
+	engineRoot := filepath.Join(base, "UE5-Custom")
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		t.Fatal("Failed to create temp dir for Output.")
+	}
+	if err := os.MkdirAll(engineRoot, 0755); err != nil {
+		t.Fatal("Failed to create temp dir for the engine.")
+	}
+	if err := os.WriteFile(filepath.Join(engineRoot, "RunUAT.bat"), nil, 0755); err != nil {
+		t.Fatal("Failed to create build script.")
+	}
+	var config = model.Config{
+		OutputBaseDirectory: outputPath,
+		BuildScriptPath:     "RunUAT.bat",
+		PluginPath:          makeFile(t.TempDir(), "MyPlugin.uplugin", t),
+		Engines:             map[string]string{"5.4": engineRoot},
+	}
+
+	// when
+	actual := len(ValidateConfig(&config)) == 0
+
+	// then
+	if !actual {
+		t.Error("Mapped engines should make the engine base directory optional.")
+	}
+}
+
+func TestMissingMappedEngineShouldFailValidation(t *testing.T) {
+	// given
+	base := t.TempDir()
+	var config = model.Config{
+		Engines: map[string]string{"5.4": filepath.Join(base, "Missing")},
+	}
+
+	// when
+	actual := len(validateEngines(&config)) == 0
+
+	// then
+	if actual {
+		t.Error("A mapped engine that doesn't exist should fail the validation.")
+	}
+}
+
+func TestValidateConfigShouldReportEveryProblem(t *testing.T) {
+	// given
+	base := t.TempDir()
+	config := model.Config{
+		EngineBaseDirectory:   filepath.Join(base, "Engines"),
+		LauncherInstalledPath: filepath.Join(base, "LauncherInstalled.dat"),
+		OutputBaseDirectory:   filepath.Join(base, "Output"),
+		OutputNameTemplate:    "{{.Missing}}",
+		TargetPlatforms:       []string{"Win64+Linux"},
+		ExtraUatArgs:          map[string][]string{"newer than 5.3": {"-StrictIncludes"}},
+		Versions:              map[string]model.VersionConfig{"5.x": {}},
+		Retry:                 &model.RetryConfig{RetryablePatterns: []string{"("}},
+	}
+
+	// when
+	problems := ValidateConfig(&config)
+
+	// then
+	fields := map[string]bool{}
+	for _, problem := range problems {
+		fields[problem.Field] = true
+		if problem.Problem == "" || problem.Fix == "" {
+			t.Errorf("Every problem should tell what's wrong and how to fix it, got %+v", problem)
+		}
+	}
+	expected := []string{"launcherInstalledPath", "engineBaseDirectory", "outputBaseDirectory", "pluginPath", "buildScriptPath", "outputNameTemplate",
+		"targetPlatforms", "extraUatArgs", "versions", "retry.retryablePatterns"}
+	for _, field := range expected {
+		if !fields[field] {
+			t.Errorf("A problem with %s should be reported, got %v", field, problems)
+		}
+	}
+}
+
+func TestMissingPluginPathShouldPointToTheConfig(t *testing.T) {
+	// when
+	problems := validatePluginPath("")
+
+	// then
+	if len(problems) != 1 || !strings.Contains(problems[0].Fix, "config.json") || strings.Contains(problems[0].Error(), "--") {
+		t.Errorf("The fix should refer to pluginPath in config.json, got %v", problems)
+	}
+}
+
+func TestCreateConfigShouldRejectUnknownFields(t *testing.T) {
+	// given
+	configPath := filepath.Join(t.TempDir(), model.ConfigFile)
+	if err := os.WriteFile(configPath, []byte(`{"pluginPath": "MyPlugin.uplugin", "docPath": "Docs.pdf"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// when
+	_, err := CreateConfig(configPath)
+
+	// then
+	var problems ValidationErrors
+	if !errors.As(err, &problems) || len(problems) != 1 {
+		t.Fatalf("The unknown field should be reported as a problem, got %v", err)
+	}
+	if problems[0].Field != "docPath" || !strings.Contains(problems[0].Fix, "docsPath") {
+		t.Errorf("The typo should be reported with the field it's most likely meant to be, got %+v", problems[0])
+	}
+}
+
+func TestCreateConfigShouldReportTheFieldOfTheWrongType(t *testing.T) {
+	// given
+	configPath := filepath.Join(t.TempDir(), model.ConfigFile)
+	if err := os.WriteFile(configPath, []byte(`{"targetPlatforms": "Win64"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// when
+	_, err := CreateConfig(configPath)
+
+	// then
+	var problems ValidationErrors
+	if !errors.As(err, &problems) || problems[0].Field != "targetPlatforms" {
+		t.Errorf("The field of the wrong type should be reported, got %v", err)
+	}
+}
+
+func TestApplyProfileShouldRejectUnknownFields(t *testing.T) {
+	// given
+	config := model.Config{Profiles: map[string]json.RawMessage{"fab": json.RawMessage(`{"outputBaseDirectry": "Fab"}`)}}
+
+	// when
+	err := ApplyProfile(&config, "fab")
+
+	// then
+	var problems ValidationErrors
+	if !errors.As(err, &problems) || !strings.Contains(problems[0].Fix, "outputBaseDirectory") {
+		t.Errorf("The typo in the profile should be reported, got %v", err)
+	}
+}
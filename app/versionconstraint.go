@@ -0,0 +1,104 @@
+// compares engine versions against constraints like ">=5.3" or ">=4.26,<5.0".
+package app
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var constraintOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+/*
+Determines if the engine version satisfies the constraint.
+A constraint is "*", or comma-separated terms that all need to match, each an operator followed by a version.
+A term without an operator needs the exact version.
+*/
+func matchesVersionConstraint(version string, constraint string) (bool, error) {
+	parsedVersion, err := parseEngineVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "*" {
+		return true, nil
+	}
+
+	matched := true
+	for _, term := range strings.Split(constraint, ",") {
+		operator, termVersion := splitConstraintTerm(strings.TrimSpace(term))
+		parsedTermVersion, err := parseEngineVersion(termVersion)
+		if err != nil {
+			return false, errors.New("invalid version constraint " + strconv.Quote(constraint) + ": " + err.Error())
+		}
+
+		matched = matched && compareWithOperator(compareEngineVersions(parsedVersion, parsedTermVersion), operator)
+	}
+
+	return matched, nil
+}
+
+/*
+Checks the constraint's syntax without matching it against anything.
+*/
+func ValidateVersionConstraint(constraint string) error {
+	_, err := matchesVersionConstraint("0.0", constraint)
+	return err
+}
+
+func splitConstraintTerm(term string) (string, string) {
+	for _, operator := range constraintOperators {
+		if strings.HasPrefix(term, operator) {
+			return operator, strings.TrimSpace(strings.TrimPrefix(term, operator))
+		}
+	}
+	return "=", term
+}
+
+func compareWithOperator(comparison int, operator string) bool {
+	switch operator {
+	case ">=":
+		return comparison >= 0
+	case "<=":
+		return comparison <= 0
+	case ">":
+		return comparison > 0
+	case "<":
+		return comparison < 0
+	case "!=":
+		return comparison != 0
+	default:
+		return comparison == 0
+	}
+}
+
+// major, minor and patch numbers of a version like 5.3 or 5.3.2, the missing ones being zero
+func parseEngineVersion(version string) ([3]int, error) {
+	var parsed [3]int
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) > len(parsed) {
+		return parsed, errors.New("version " + strconv.Quote(version) + " has too many parts")
+	}
+
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return parsed, errors.New("version " + strconv.Quote(version) + " must be MAJOR.MINOR or MAJOR.MINOR.PATCH")
+		}
+		parsed[i] = number
+	}
+	return parsed, nil
+}
+
+func compareEngineVersions(a [3]int, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"strconv"
+	"testing"
+)
+
+type versionConstraintTestData struct {
+	version    string
+	constraint string
+	expected   bool
+}
+
+func TestMatchesVersionConstraint(t *testing.T) {
+	for i, tt := range createVersionConstraintTestData() {
+		t.Run("VersionConstraintTest #"+strconv.Itoa(i), func(t *testing.T) {
+			// when
+			actual, err := matchesVersionConstraint(tt.version, tt.constraint)
+
+			// then
+			if err != nil || actual != tt.expected {
+				t.Errorf("%s against %q: expected %v, actual %v (%v)", tt.version, tt.constraint, tt.expected, actual, err)
+			}
+		})
+	}
+}
+
+func TestInvalidVersionConstraintShouldReturnError(t *testing.T) {
+	for _, constraint := range []string{">=five", "5.x", ">=5.3,", "5.3.1.0"} {
+		if err := ValidateVersionConstraint(constraint); err == nil {
+			t.Errorf("Constraint %q should have been rejected.", constraint)
+		}
+	}
+}
+
+// test data
+func createVersionConstraintTestData() []versionConstraintTestData {
+	return []versionConstraintTestData{
+		{"5.3", "*", true},
+		{"5.3", ">=5.3", true},
+		{"5.2", ">=5.3", false},
+		{"5.10", ">5.9", true},
+		{"4.27", "<5.0", true},
+		{"5.0", "<5.0", false},
+		{"5.4", ">=5.0,<5.4", false},
+		{"5.3", ">=5.0,<5.4", true},
+		{"5.3", "5.3", true},
+		{"5.3", "==5.4", false},
+		{"5.3", "!=5.4", true},
+		{"5.3.2", "<=5.3", false},
+	}
+}
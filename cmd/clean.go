@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"unreal-plugin-release/app"
+	"unreal-plugin-release/model"
+)
+
+var cleanInput struct {
+	keepLast            int
+	maxAgeDays          int
+	removeLastOfVersion bool
+	dryRun              bool
+	yes                 bool
+}
+
+func init() {
+	cleanCmd.Flags().IntVar(&cleanInput.keepLast, "keep-last", 0, "The number of releases kept per engine version, overriding retention.keepLast of the config")
+	cleanCmd.Flags().IntVar(&cleanInput.maxAgeDays, "max-age-days", 0, "Remove releases older than this, overriding retention.maxAgeDays of the config")
+	cleanCmd.Flags().BoolVar(&cleanInput.removeLastOfVersion, "remove-last-of-version", false, "Let max-age-days remove even the newest release of an engine version, when keep-last isn't set")
+	cleanCmd.Flags().BoolVar(&cleanInput.dryRun, "dry-run", false, "Only show what would be removed")
+	cleanCmd.Flags().BoolVar(&cleanInput.yes, "yes", false, "Remove without asking")
+	addConfigFlags(cleanCmd)
+	rootCmd.AddCommand(cleanCmd)
+
+	addConfigFlags(tagPublishedCmd)
+	rootCmd.AddCommand(tagPublishedCmd)
+}
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove old releases from the output directory.",
+	Long: `Remove the old releases, their archives and checksums from outputBaseDirectory, following the retention of the config
+or the flags: keep the last N releases of every engine version, and remove the ones older than X days.
+The last keepLast of every engine version are kept however old they are, and maxAgeDays alone keeps the newest one,
+unless --remove-last-of-version is set.
+The releases tagged as published are always kept, and only what the tool created itself is removed. The plan is shown, and confirmed, before anything is removed.`,
+	Args: cobra.NoArgs,
+	Run:  runCleanCommand,
+}
+
+var tagPublishedCmd = &cobra.Command{
+	Use:   "tag-published <release>...",
+	Short: "Tag releases as published, so clean always keeps them.",
+	Long: `Tag releases of outputBaseDirectory, by their folder name, as published, e.g. the ones uploaded to Fab.
+clean and the retention after a batch always keep them.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runTagPublishedCommand,
+}
+
+func runCleanCommand(cmd *cobra.Command, args []string) {
+	config, _ := loadValidConfig()
+
+	retention := model.RetentionConfig{}
+	if config.Retention != nil {
+		retention = *config.Retention
+	}
+	if cmd.Flags().Changed("keep-last") {
+		retention.KeepLast = cleanInput.keepLast
+	}
+	if cmd.Flags().Changed("max-age-days") {
+		retention.MaxAgeDays = cleanInput.maxAgeDays
+	}
+	if cmd.Flags().Changed("remove-last-of-version") {
+		retention.RemoveLastOfVersion = cleanInput.removeLastOfVersion
+	}
+
+	plan, err := app.PlanRetention(config, retention, time.Now())
+	if err != nil {
+		logger.Error("clean failed", "error", err)
+		os.Exit(1)
+	}
+
+	out := cmd.OutOrStdout()
+	deletions := printRetentionPlan(out, plan)
+	if deletions == 0 || cleanInput.dryRun {
+		return
+	}
+
+	if !cleanInput.yes {
+		prompt := newPrompter(cmd.InOrStdin(), out, false)
+		answer, err := prompt.ask(fmt.Sprintf("Remove %d releases?", deletions), "no")
+		if err != nil || (!strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes")) {
+			logger.Info("nothing removed")
+			return
+		}
+	}
+
+	removed, err := app.ApplyRetention(config, plan, logger)
+	if err != nil {
+		logger.Error("clean failed", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("old releases removed", "count", removed)
+	if removed < deletions {
+		logger.Error("some releases could not be removed, see the warnings above", "count", deletions-removed)
+		os.Exit(1)
+	}
+}
+
+// a line per release with what happens to it and why, returning the number of releases removed
+func printRetentionPlan(out io.Writer, plan []app.RetentionEntry) int {
+	if len(plan) == 0 {
+		fmt.Fprintln(out, "No releases found.")
+		return 0
+	}
+
+	deletions := 0
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ACTION\tRELEASE\tUE\tMODIFIED\tREASON")
+	for _, entry := range plan {
+		action := "keep"
+		if entry.Delete {
+			action = "delete"
+			deletions++
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", action, entry.Name, entry.Version, entry.ModTime.Format("2006-01-02 15:04"), entry.Reason)
+	}
+	table.Flush()
+	fmt.Fprintf(out, "\n%d of %d releases to remove\n", deletions, len(plan))
+	return deletions
+}
+
+func runTagPublishedCommand(cmd *cobra.Command, args []string) {
+	config, _ := loadValidConfig()
+	for _, name := range args {
+		if err := app.TagPublished(config, name); err != nil {
+			logger.Error("tagging failed", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("tagged as published", "release", name)
+	}
+}
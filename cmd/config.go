@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"unreal-plugin-release/app"
+	"unreal-plugin-release/model"
+)
+
+var schemaOutput string
+
+func init() {
+	configSchemaCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "The file to write the schema to, stdout if empty")
+	configCmd.AddCommand(configSchemaCmd)
+	addConfigFlags(configShowCmd)
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with config.json.",
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of config.json.",
+	Long: `Print the JSON Schema of config.json, which editors use to complete and check the config.
+Save it next to the config and refer to it from the config:
+
+  unreal-plugin-release config schema -o config.schema.json
+
+  {"$schema": "./config.schema.json", ...}`,
+	Args: cobra.NoArgs,
+	Run:  runConfigSchemaCommand,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective config and where each value came from.",
+	Long: `Print every field of the config with its effective value and where it came from:
+flag, env, profile, file or default, in the order they take precedence.
+Every field has a flag, like --output-base-directory, and an environment variable, like UPR_OUTPUT_BASE_DIRECTORY.
+The config is not validated, so it can be shown even while it's broken.`,
+	Args: cobra.NoArgs,
+	Run:  runConfigShowCommand,
+}
+
+func runConfigShowCommand(cmd *cobra.Command, args []string) {
+	execPath, err := os.Executable()
+	if err != nil {
+		panic("Executable file not found")
+	}
+
+	configPath := app.GetFullPathForFileInExecDir(execPath, model.ConfigFile)
+	config, sources, err := app.LoadConfig(configPath, configLayers())
+	if err != nil {
+		logConfigError(configPath, err)
+		os.Exit(1)
+	}
+	printEffectiveConfig(cmd.OutOrStdout(), config, sources)
+}
+
+// a line per field with its source and value, as it would be written in config.json
+func printEffectiveConfig(out io.Writer, config *model.Config, sources map[string]app.ConfigSource) {
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "FIELD\tSOURCE\tVALUE")
+	for _, field := range app.OverridableConfigFields() {
+		source := string(sources[field.Name])
+		switch sources[field.Name] {
+		case app.SourceFlag:
+			source += " --" + field.Flag
+		case app.SourceEnv:
+			source += " " + field.Env
+		case app.SourceProfile:
+			source += " " + cmdInput.Profile
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", field.Name, source, app.ConfigFieldValue(config, field.Name))
+	}
+	table.Flush()
+}
+
+func runConfigSchemaCommand(cmd *cobra.Command, args []string) {
+	schema, err := app.ConfigSchema()
+	if err != nil {
+		logger.Error("failed to generate the schema", "error", err)
+		os.Exit(1)
+	}
+
+	if schemaOutput == "" {
+		cmd.OutOrStdout().Write(schema)
+		return
+	}
+	if err := os.WriteFile(schemaOutput, schema, 0644); err != nil {
+		logger.Error("failed to write the schema", "path", schemaOutput, "error", err)
+		os.Exit(1)
+	}
+	logger.Info("schema written", "path", schemaOutput)
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"unreal-plugin-release/app"
+	"unreal-plugin-release/model"
+	"unreal-plugin-release/release"
+)
+
+const (
+	dashboardRefresh   = 500 * time.Millisecond
+	dashboardTailLines = 3
+	dashboardMessages  = 5
+	dashboardWidth     = 120
+)
+
+const (
+	rowPending = "pending"
+	rowRunning = "running"
+	rowDone    = "done"
+	rowFailed  = "failed"
+)
+
+/*
+A row per version, redrawn in place while the batch runs: its state, current stage, elapsed time,
+the time left based on the previous build report, and the last lines UAT printed.
+Warnings and errors are listed under the rows, the rest of the log is left out.
+*/
+type dashboard struct {
+	mu        sync.Mutex
+	out       io.Writer
+	rows      []*dashboardRow
+	byVersion map[string]*dashboardRow
+	messages  []string
+	// how long every version took in the previous batch
+	expected   map[string]time.Duration
+	level      slog.Level
+	width      int
+	drawnLines int
+	now        func() time.Time
+	stopped    chan struct{}
+	done       sync.WaitGroup
+}
+
+type dashboardRow struct {
+	version  string
+	state    string
+	stage    string
+	started  time.Time
+	finished time.Time
+	tail     []string
+}
+
+func newDashboard(out io.Writer, versions []string, expected map[string]time.Duration, level slog.Level) *dashboard {
+	d := &dashboard{
+		out:       out,
+		byVersion: map[string]*dashboardRow{},
+		expected:  expected,
+		level:     level,
+		width:     terminalWidth(),
+		now:       time.Now,
+	}
+	for _, version := range versions {
+		row := &dashboardRow{version: version, state: rowPending}
+		d.rows = append(d.rows, row)
+		d.byVersion[version] = row
+	}
+	return d
+}
+
+// redraws the dashboard periodically, so the elapsed times move even while nothing happens
+func (d *dashboard) start() {
+	d.stopped = make(chan struct{})
+	d.done.Add(1)
+	go func() {
+		defer d.done.Done()
+		ticker := time.NewTicker(dashboardRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.redraw()
+			case <-d.stopped:
+				return
+			}
+		}
+	}()
+}
+
+// stops redrawing, leaving the final state of the rows on the screen
+func (d *dashboard) stop() {
+	close(d.stopped)
+	d.done.Wait()
+	d.redraw()
+}
+
+func (d *dashboard) handleEvent(event release.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch e := event.(type) {
+	case release.VersionStarted:
+		row := d.row(e.Version)
+		row.state, row.started = rowRunning, d.now()
+	case release.StageStarted:
+		d.row(e.Version).stage = e.Stage
+	case release.VersionFinished:
+		row := d.row(e.Version)
+		row.state, row.finished = rowDone, d.now()
+		if e.Err != nil {
+			row.state = rowFailed
+		}
+	}
+}
+
+// a version missing from the plan, e.g. when the hooks or the profile change it, still gets a row
+func (d *dashboard) row(version string) *dashboardRow {
+	row, ok := d.byVersion[version]
+	if !ok {
+		row = &dashboardRow{version: version, state: rowPending}
+		d.rows = append(d.rows, row)
+		d.byVersion[version] = row
+	}
+	return row
+}
+
+// the logger of the batch, whose records end up on the dashboard instead of the output
+func (d *dashboard) logger() *slog.Logger {
+	return slog.New(&dashboardHandler{dashboard: d})
+}
+
+func (d *dashboard) addLine(version string, line string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	row := d.row(version)
+	row.tail = append(row.tail, line)
+	if len(row.tail) > dashboardTailLines {
+		row.tail = row.tail[len(row.tail)-dashboardTailLines:]
+	}
+}
+
+func (d *dashboard) addMessage(message string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.messages = append(d.messages, message)
+	if len(d.messages) > dashboardMessages {
+		d.messages = d.messages[len(d.messages)-dashboardMessages:]
+	}
+}
+
+// moves the cursor back over the previous drawing and writes the current one over it
+func (d *dashboard) redraw() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var screen strings.Builder
+	if d.drawnLines > 0 {
+		fmt.Fprintf(&screen, "\x1b[%dA", d.drawnLines)
+	}
+	screen.WriteString("\x1b[J")
+
+	lines := d.render()
+	for _, line := range lines {
+		// a line filling the whole width wraps on some consoles, which would throw off the lines to move back over
+		screen.WriteString(truncate(line, d.width-1))
+		screen.WriteString("\n")
+	}
+	d.drawnLines = len(lines)
+	io.WriteString(d.out, screen.String())
+}
+
+func (d *dashboard) render() []string {
+	now := d.now()
+	var lines []string
+	for _, row := range d.rows {
+		lines = append(lines, d.renderRow(row, now))
+		if row.state == rowRunning {
+			for _, line := range row.tail {
+				lines = append(lines, "           │ "+line)
+			}
+		}
+	}
+	for _, message := range d.messages {
+		lines = append(lines, message)
+	}
+	return lines
+}
+
+func (d *dashboard) renderRow(row *dashboardRow, now time.Time) string {
+	expected, known := d.expected[row.version]
+	timing := ""
+	switch row.state {
+	case rowPending:
+		if known {
+			timing = "~" + formatDuration(expected)
+		}
+	case rowRunning:
+		elapsed := now.Sub(row.started)
+		timing = formatDuration(elapsed)
+		if known {
+			timing += "  ETA " + formatDuration(max(expected-elapsed, 0))
+		}
+	default:
+		timing = formatDuration(row.finished.Sub(row.started))
+	}
+
+	return fmt.Sprintf("UE %-6s %s %-8s %-9s %s", row.version, stateSymbols[row.state], row.state, row.stage, timing)
+}
+
+var stateSymbols = map[string]string{
+	rowPending: "·",
+	rowRunning: "▶",
+	rowDone:    "✔",
+	rowFailed:  "✘",
+}
+
+func formatDuration(duration time.Duration) string {
+	return duration.Round(time.Second).String()
+}
+
+func truncate(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// the width of the console, or the one the shell reports where the console can't be asked
+func terminalWidth() int {
+	if columns := consoleWidth(os.Stdout); columns > 10 {
+		return columns
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 10 {
+		return columns
+	}
+	return dashboardWidth
+}
+
+/*
+Puts the records of the batch on the dashboard: the lines of the subprocesses under the row of their version,
+warnings and errors under the rows. Everything else is already shown by the rows.
+*/
+type dashboardHandler struct {
+	dashboard *dashboard
+	attrs     []slog.Attr
+}
+
+func (h *dashboardHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+func (h *dashboardHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := map[string]string{}
+	var text strings.Builder
+	text.WriteString(record.Message)
+	addField := func(attr slog.Attr) bool {
+		fields[attr.Key] = attr.Value.String()
+		fmt.Fprintf(&text, " %s=%s", attr.Key, attr.Value)
+		return true
+	}
+	for _, attr := range h.attrs {
+		addField(attr)
+	}
+	record.Attrs(addField)
+
+	if fields["subprocess"] != "" && fields["version"] != "" {
+		h.dashboard.addLine(fields["version"], record.Message)
+		return nil
+	}
+	if record.Level >= max(slog.LevelWarn, h.dashboard.level) {
+		h.dashboard.addMessage(record.Level.String() + " " + text.String())
+	}
+	return nil
+}
+
+func (h *dashboardHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dashboardHandler{dashboard: h.dashboard, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+// groups are not used by the batch, their attributes are shown without the group
+func (h *dashboardHandler) WithGroup(name string) slog.Handler {
+	return h
+}
+
+/*
+The dashboard is only drawn on a terminal that understands its escape sequences.
+Redirected output, machine readable logs, and a console that can't be switched to them get plain records.
+*/
+func isDashboardWanted() bool {
+	if logOptions.plain || logOptions.quiet || strings.ToLower(logOptions.format) != "text" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0 && enableTerminalEscapes(os.Stdout)
+}
+
+// how long the versions took the last time they were built, whichever batch built them
+func previousDurations(config *model.Config) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+	seconds, err := app.ReadBuildDurations(filepath.Join(config.OutputBaseDirectory, model.BuildDurationsFile))
+	if err != nil {
+		return durations
+	}
+	for version, duration := range seconds {
+		if duration > 0 {
+			durations[version] = time.Duration(duration * float64(time.Second))
+		}
+	}
+	return durations
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"unreal-plugin-release/app"
+	"unreal-plugin-release/model"
+)
+
+func init() {
+	doctorCmd.Flags().StringVar(&cmdInput.EngineVersions, "engine-versions", "", "Comma-separated list of Unreal engine versions to check, every engine found if empty")
+	addConfigFlags(doctorCmd)
+	rootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the config and the environment, item by item.",
+	Long: `Check every path of config.json, the build script and engine version of every requested version,
+write permission and free disk space in the output directory, the .uplugin file, and whether the docs and FilterPlugin.ini match.
+Every item passes, warns or fails with its reason. The command exits with an error if any item fails.`,
+	Run: runDoctorCommand,
+}
+
+var checkSymbols = map[app.CheckStatus]string{
+	app.CheckPass: "✅",
+	app.CheckWarn: "⚠️",
+	app.CheckFail: "❌",
+}
+
+func runDoctorCommand(cmd *cobra.Command, args []string) {
+	if cmdInput.EngineVersions != "" && !isEngineVersionsValid() {
+		os.Exit(1)
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		panic("Executable file not found")
+	}
+
+	configPath := app.GetFullPathForFileInExecDir(execPath, model.ConfigFile)
+	results := []app.CheckResult{{Item: "config", Status: app.CheckPass, Detail: configPath}}
+	config, _, err := app.LoadConfig(configPath, configLayers())
+	var problems app.ValidationErrors
+	if errors.As(err, &problems) {
+		results = nil
+		for _, problem := range problems {
+			results = append(results, app.CheckResult{Item: problem.Field, Status: app.CheckFail, Detail: problem.Error()})
+		}
+	} else if err != nil {
+		results[0] = app.CheckResult{Item: "config", Status: app.CheckFail, Detail: err.Error()}
+	} else {
+		var versions []string
+		if cmdInput.EngineVersions != "" {
+			versions = strings.Split(cmdInput.EngineVersions, ",")
+		}
+		results = append(results, app.RunDoctor(config, versions, execPath)...)
+	}
+
+	if printCheckResults(cmd.OutOrStdout(), results) > 0 {
+		os.Exit(1)
+	}
+}
+
+// prints a line per item and a summary, returning the number of failures
+func printCheckResults(out io.Writer, results []app.CheckResult) int {
+	counts := map[app.CheckStatus]int{}
+	for _, result := range results {
+		counts[result.Status]++
+		fmt.Fprintf(out, "%s %-4s  %-24s %s\n", checkSymbols[result.Status], result.Status, result.Item, result.Detail)
+	}
+	fmt.Fprintf(out, "\n%d passed, %d warnings, %d failed\n", counts[app.CheckPass], counts[app.CheckWarn], counts[app.CheckFail])
+	return counts[app.CheckFail]
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"unreal-plugin-release/model"
+	"unreal-plugin-release/release"
+)
+
+// the CLI's view of a batch: a record for every finished stage and every file it kept, failures are logged from the returned error
+func logEvent(event release.Event) {
+	switch e := event.(type) {
+	case release.StageFinished:
+		if e.Err == nil {
+			logger.Info("stage finished", "version", e.Version, "stage", e.Stage, "duration", e.Duration)
+		}
+	case release.ArtifactWritten:
+		switch e.Kind {
+		case model.ArtifactRelease:
+			logger.Info("published", "version", e.Version, "path", e.Path)
+		case model.ArtifactReport:
+			logger.Info("build report written", "path", e.Path)
+		default:
+			logger.Debug("artifact written", "version", e.Version, "kind", e.Kind, "path", e.Path)
+		}
+	}
+}
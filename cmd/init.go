@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"unreal-plugin-release/app"
+	"unreal-plugin-release/model"
+)
+
+var initInput struct {
+	engineBaseDirectory string
+	buildScriptPath     string
+	outputDirectory     string
+	docsPath            string
+	docsPathInPlugin    string
+	force               bool
+	nonInteractive      bool
+}
+
+func init() {
+	initCmd.Flags().StringVar(&initInput.engineBaseDirectory, "engine-base-directory", "", "The folder containing the UE_<version> engine folders")
+	initCmd.Flags().StringVar(&initInput.buildScriptPath, "build-script-path", "", "The path of RunUAT within an engine folder")
+	initCmd.Flags().StringVar(&initInput.outputDirectory, "output-directory", "", "The folder the releases are written to")
+	initCmd.Flags().StringVar(&initInput.docsPath, "docs", "", "The pdf documentation to ship with the plugin")
+	initCmd.Flags().StringVar(&initInput.docsPathInPlugin, "docs-path-in-plugin", "", "Where the documentation goes inside the released plugin, e.g. /Docs/MyPlugin.pdf")
+	initCmd.Flags().BoolVar(&initInput.force, "force", false, "Replace an existing config.json and FilterPlugin.ini")
+	initCmd.Flags().BoolVar(&initInput.nonInteractive, "non-interactive", false, "Don't ask anything, take the answers from the flags and the suggestions")
+	rootCmd.AddCommand(initCmd)
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init <path to .uplugin>",
+	Short: "Create config.json and FilterPlugin.ini for a plugin.",
+	Long: `Create config.json, and FilterPlugin.ini if the plugin ships with documentation, next to the executable.
+The installed engines are detected, and an output directory next to the project is suggested.
+Every answer can be given as a flag, with --non-interactive nothing is asked and the suggestions fill in the rest.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runInitCommand,
+}
+
+func runInitCommand(cmd *cobra.Command, args []string) {
+	execPath, err := os.Executable()
+	if err != nil {
+		panic("Executable file not found")
+	}
+
+	prompt := newPrompter(cmd.InOrStdin(), cmd.OutOrStdout(), initInput.nonInteractive)
+	answers, err := askScaffoldAnswers(prompt, args)
+	if err != nil {
+		logger.Error("init failed", "error", err)
+		os.Exit(1)
+	}
+
+	configPath := app.GetFullPathForFileInExecDir(execPath, model.ConfigFile)
+	iniPath := app.GetFullPathForFileInExecDir(execPath, model.PluginConfigurationIniFileName)
+	if err := app.WriteScaffold(answers, configPath, iniPath, initInput.force); err != nil {
+		logger.Error("init failed", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("config written", "path", configPath)
+	if answers.DocsPath != "" {
+		logger.Info("FilterPlugin.ini written", "path", iniPath)
+	}
+	if _, err := createAndValidateConfig(configPath); err != nil {
+		logger.Warn("the config was written, but it doesn't pass validation yet, fix the problems above before building", "path", configPath)
+	}
+}
+
+// the flags win over the suggestions, and are offered as the default answers of the questions
+func askScaffoldAnswers(prompt *prompter, args []string) (app.ScaffoldAnswers, error) {
+	pluginPath := ""
+	if len(args) > 0 {
+		pluginPath = args[0]
+	}
+	pluginPath, err := prompt.ask("Path of the .uplugin file", pluginPath)
+	if err != nil {
+		return app.ScaffoldAnswers{}, err
+	}
+	if pluginPath == "" {
+		return app.ScaffoldAnswers{}, errors.New("the path of the .uplugin file is required")
+	}
+	if absolute, err := filepath.Abs(pluginPath); err == nil {
+		pluginPath = absolute
+	}
+
+	answers, engines := app.SuggestScaffold(pluginPath)
+	prompt.say("Enter keeps the answer in brackets, - clears it.")
+	prompt.say("Engines found:")
+	if len(engines) == 0 {
+		prompt.say("  none, set the engine base directory or add engines to config.json later")
+	}
+	for _, engine := range engines {
+		prompt.say(fmt.Sprintf("  UE %-6s %s (from %s)", engine.Version, engine.Root, engine.Source))
+	}
+
+	questions := []struct {
+		question string
+		flag     string
+		answer   *string
+	}{
+		{"Engine base directory, containing the UE_<version> folders (- if every engine is found above)", initInput.engineBaseDirectory, &answers.EngineBaseDirectory},
+		{"Build script path within an engine folder", initInput.buildScriptPath, &answers.BuildScriptPath},
+		{"Output directory of the releases", initInput.outputDirectory, &answers.OutputBaseDirectory},
+		{"Documentation pdf (empty for none)", initInput.docsPath, &answers.DocsPath},
+	}
+	for _, q := range questions {
+		if q.flag != "" {
+			*q.answer = q.flag
+		}
+		if *q.answer, err = prompt.ask(q.question, *q.answer); err != nil {
+			return answers, err
+		}
+	}
+
+	if answers.DocsPath == "" {
+		return answers, nil
+	}
+	answers.DocsPathInPlugin = app.SuggestDocsPathInPlugin(answers.DocsPath)
+	if initInput.docsPathInPlugin != "" {
+		answers.DocsPathInPlugin = initInput.docsPathInPlugin
+	}
+	answers.DocsPathInPlugin, err = prompt.ask("Path of the documentation inside the released plugin", answers.DocsPathInPlugin)
+	return answers, err
+}
+
+// asks questions on the terminal, or takes the defaults without asking
+type prompter struct {
+	in             *bufio.Reader
+	out            io.Writer
+	nonInteractive bool
+}
+
+func newPrompter(in io.Reader, out io.Writer, nonInteractive bool) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out, nonInteractive: nonInteractive}
+}
+
+func (p *prompter) say(line string) {
+	if !p.nonInteractive {
+		fmt.Fprintln(p.out, line)
+	}
+}
+
+// an empty answer keeps the default, a - clears it
+func (p *prompter) ask(question string, defaultAnswer string) (string, error) {
+	if p.nonInteractive {
+		return defaultAnswer, nil
+	}
+
+	fmt.Fprintf(p.out, "%s [%s]: ", question, defaultAnswer)
+	line, err := p.in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("no answer to %q: %w", question, err)
+	}
+
+	answer := strings.Trim(strings.TrimSpace(line), `"`)
+	switch answer {
+	case "":
+		return defaultAnswer, nil
+	case "-":
+		return "", nil
+	}
+	return answer, nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"unreal-plugin-release/app"
+)
+
+func init() {
+	addConfigFlags(listEnginesCmd)
+	rootCmd.AddCommand(listEnginesCmd)
+}
+
+var listEnginesCmd = &cobra.Command{
+	Use:   "list-engines",
+	Short: "List the engines the plugin can be built with.",
+	Long: `List every engine the plugin can be built with: the versions mapped in engines of config.json,
+the UE_<version> folders in engineBaseDirectory, and the engines installed by the Epic Launcher.
+A mapping takes priority over a folder of the same version, which takes priority over the launcher.`,
+	Run: runListEnginesCommand,
+}
+
+func runListEnginesCommand(cmd *cobra.Command, args []string) {
+	config, _ := loadValidConfig()
+
+	engines := app.NewEngineResolver(config).FindEngines()
+	if len(engines) == 0 {
+		logger.Warn("no engines found")
+		return
+	}
+
+	for _, engine := range engines {
+		if !app.IsFile(engine.BuildScriptPath) {
+			logger.Warn("engine found, but its build script is missing", "version", engine.Version, "path", engine.Root, "source", engine.Source, "buildScript", engine.BuildScriptPath)
+			continue
+		}
+		logger.Info("engine found", "version", engine.Version, "path", engine.Root, "source", engine.Source, "buildScript", engine.BuildScriptPath)
+	}
+}
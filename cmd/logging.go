@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var logOptions struct {
+	level  string
+	format string
+	quiet  bool
+	plain  bool
+	// the level parsed from the flags
+	minLevel slog.Level
+}
+
+// where the commands write what they're doing, set up from the flags before any command runs
+var logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logOptions.level, "log-level", "info", "Minimum level of the logged records: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logOptions.format, "log-format", "text", "Format of the logged records: text or json")
+	rootCmd.PersistentFlags().BoolVar(&logOptions.quiet, "quiet", false, "Only log errors, the same as --log-level=error")
+	rootCmd.PersistentFlags().BoolVar(&logOptions.plain, "plain", false, "Log plain records instead of showing the progress dashboard on a terminal")
+	rootCmd.PersistentPreRunE = setUpLogger
+}
+
+func setUpLogger(cmd *cobra.Command, args []string) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logOptions.level)); err != nil {
+		return fmt.Errorf("invalid --log-level %q, must be debug, info, warn or error", logOptions.level)
+	}
+	if logOptions.quiet {
+		level = slog.LevelError
+	}
+
+	logOptions.minLevel = level
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(logOptions.format) {
+	case "text":
+		logger = slog.New(slog.NewTextHandler(os.Stdout, handlerOptions))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, handlerOptions))
+	default:
+		return fmt.Errorf("invalid --log-format %q, must be text or json", logOptions.format)
+	}
+	slog.SetDefault(logger)
+	return nil
+}
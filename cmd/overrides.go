@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"unreal-plugin-release/app"
+)
+
+// the config fields given as flags, by json name
+var flagOverrides = map[string]string{}
+
+// a flag per overridable config field, shared by the commands loading the config
+var configFlags = newConfigFlags()
+
+// a flag overriding a config field, remembering only the values actually given
+type configFlag struct {
+	field app.ConfigField
+	value string
+}
+
+func (f *configFlag) String() string {
+	return f.value
+}
+
+func (f *configFlag) Set(value string) error {
+	f.value = value
+	flagOverrides[f.field.Name] = value
+	return nil
+}
+
+func (f *configFlag) Type() string {
+	return f.field.Format
+}
+
+func newConfigFlags() []*configFlag {
+	var flags []*configFlag
+	for _, field := range app.OverridableConfigFields() {
+		flags = append(flags, &configFlag{field: field})
+	}
+	return flags
+}
+
+// adds a flag for every config field to the command, overriding config.json, the profile and the environment
+func addConfigFlags(cmd *cobra.Command) {
+	for _, flag := range configFlags {
+		cmd.Flags().Var(flag, flag.field.Flag, "Overrides "+flag.field.Name+" of the config, also set by "+flag.field.Env)
+	}
+}
+
+// the profile, the environment and the flags, in the order they override config.json
+func configLayers() app.ConfigLayers {
+	return app.ConfigLayers{
+		Profile: cmdInput.Profile,
+		Env:     app.EnvOverrides(os.LookupEnv),
+		Flags:   flagOverrides,
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"unreal-plugin-release/release"
+)
+
+func init() {
+	postprocessCmd.Flags().StringVar(&cmdInput.EngineVersions, "engine-versions", "", "Comma-separated list of Unreal engine versions")
+	postprocessCmd.Flags().BoolVar(&cmdInput.SkipDocs, "skip-docs", false, "Omit copying documentation")
+	postprocessCmd.Flags().StringVar(&cmdInput.Platforms, "platforms", "", "Comma-separated list of target platforms, e.g. Win64,Linux,Android")
+	addConfigFlags(postprocessCmd)
+	rootCmd.AddCommand(postprocessCmd)
+}
+
+var postprocessCmd = &cobra.Command{
+	Use:   "postprocess",
+	Short: "Repeat the steps after the build on existing releases.",
+	Long: `Repeat the cleanup, docs, descriptor stamping, archive and checksum steps on the releases
+already in outputBaseDirectory, without building the plugin again. Useful when only zipping or copying the docs failed.
+The release of a version is the newest one whose descriptor is stamped with it, whatever its name.
+Versions without a release containing the plugin descriptor are skipped with a warning.`,
+	Run: runPostprocessCommand,
+}
+
+func runPostprocessCommand(cmd *cobra.Command, args []string) {
+	if !isEngineVersionsValid() || !isPlatformsValid() {
+		os.Exit(1)
+	}
+
+	config, execPath := loadValidConfig()
+	if err := runWithProgress(cmd.Context(), config, releaseOptions(config, execPath), release.PostProcess); err != nil {
+		logger.Error("post-processing failed", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("post-processing completed successfully")
+}
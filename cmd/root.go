@@ -4,16 +4,18 @@ Copyright © 2025 AMSIAMUN
 package cmd
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"os"
+	"os/signal"
 	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"unreal-plugin-release/app"
-	"unreal-plugin-release/executor"
 	"unreal-plugin-release/model"
+	"unreal-plugin-release/release"
 )
 
 var cmdInput = model.CmdInput{}
@@ -21,6 +23,12 @@ var cmdInput = model.CmdInput{}
 func init() {
 	rootCmd.Flags().StringVar(&cmdInput.EngineVersions, "engine-versions", "", "Comma-separated list of Unreal engine versions")
 	rootCmd.Flags().BoolVar(&cmdInput.SkipDocs, "skip-docs", false, "Omit copying documentation")
+	rootCmd.Flags().StringVar(&cmdInput.Platforms, "platforms", "", "Comma-separated list of target platforms, e.g. Win64,Linux,Android")
+	rootCmd.Flags().BoolVar(&cmdInput.PackageOnly, "package-only", false, "Copy the plugin source into the releases instead of building it")
+	rootCmd.Flags().BoolVar(&cmdInput.VerifyBuild, "verify-build", false, "With --package-only, build into a temporary folder to prove the plugin compiles")
+	rootCmd.Flags().BoolVar(&cmdInput.Resume, "resume", false, "Continue the interrupted batch of the output directory at its first incomplete version and stage")
+	rootCmd.PersistentFlags().StringVar(&cmdInput.Profile, "profile", "", "Name of the profile in config.json to apply")
+	addConfigFlags(rootCmd)
 }
 
 var rootCmd = &cobra.Command{
@@ -34,6 +42,20 @@ REQUIRES a config.json file next to the executable, which must contain:
   - outputBaseDirectory: the path to the folder that will contain the built content
   - pluginPath: the path to the .uplugin file to be built
   - docsPath: (optional) the path to the pdf documentation
+  - engines: (optional) engine root or build script path by version, for engines outside engineBaseDirectory
+  - launcherInstalledPath: (optional) the Epic Launcher's LauncherInstalled.dat, if not in its default location
+  - outputNameTemplate: (optional) Go template naming the release folders and archives
+  - profiles: (optional) named overrides of the above, selected with --profile
+  - targetPlatforms: (optional) the platforms to build for, e.g. ["Win64", "Linux"]
+  - extraUatArgs: (optional) extra BuildPlugin arguments by engine version, e.g. {">=5.3": ["-StrictIncludes"]}
+  - stages: (optional) the steps of a release in order, from build to publish, with their options
+  - hooks: (optional) commands run before or after parts of the batch, e.g. {"postArchive": "copy-to-nas.bat"}
+  - retention: (optional) which old releases clean removes, e.g. {"keepLast": 3, "afterBatch": true}
+
+Paths can use forward slashes, ~ and ${VAR}, relative ones are relative to config.json. Text fields, engines and hooks
+can be given per OS, like {"windows": "D:/Games", "linux": "~/UnrealEngine"}.
+Every field can be overridden with a flag, like --output-base-directory, or an environment variable, like UPR_OUTPUT_BASE_DIRECTORY.
+A flag wins over the environment, which wins over the profile, which wins over config.json. See config show.
 
 If documentation is enabled, a FilterPlugin.ini file must also exist next to the executable.
 It should contain the expected internal documentation path like so:
@@ -44,17 +66,31 @@ It should contain the expected internal documentation path like so:
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	// Ctrl+C cancels the batch, which stops the running build and cleans up its staging area
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		logger.Error(err.Error())
 		os.Exit(1)
 	}
 }
 
 func runRootCommand(cmd *cobra.Command, args []string) {
-	if !isEngineVersionsValid() {
+	if !isEngineVersionsValid() || !isPlatformsValid() || !isPackageModeValid() {
 		os.Exit(1)
 	}
 
+	config, execPath := loadValidConfig()
+	if err := runWithProgress(cmd.Context(), config, releaseOptions(config, execPath), release.Run); err != nil {
+		logger.Error("batch failed", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("all builds completed successfully")
+}
+
+// loads the config next to the executable, exiting if it's missing or invalid
+func loadValidConfig() (*model.Config, string) {
 	execPath, err := os.Executable()
 	if err != nil {
 		panic("Executable file not found")
@@ -65,64 +101,113 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	app.NewPluginBuilder(config, executor.NewExecutor()).BuildPluginsForSelectedVersions(cmdInput, execPath)
-	fmt.Println("✅ All builds completed successfully.")
+	return config, execPath
+}
+
+// the options of the batch from the flags, the CLI logs the progress and the events.
+// The config was loaded with the profile, the environment and the flags applied already.
+func releaseOptions(config *model.Config, execPath string) release.Options {
+	options := release.Options{
+		Config:         config,
+		Resolved:       true,
+		Profile:        cmdInput.Profile,
+		EngineVersions: strings.Split(cmdInput.EngineVersions, ","),
+		SkipDocs:       cmdInput.SkipDocs,
+		PackageOnly:    cmdInput.PackageOnly,
+		VerifyBuild:    cmdInput.VerifyBuild,
+		Resume:         cmdInput.Resume,
+		ExecPath:       execPath,
+		Logger:         logger,
+		OnEvent:        logEvent,
+	}
+	if cmdInput.Platforms != "" {
+		options.Platforms = strings.Split(cmdInput.Platforms, ",")
+	}
+	return options
+}
+
+/*
+Runs the batch with the progress shown on the dashboard if stdout is a terminal, or logged as plain records otherwise.
+*/
+func runWithProgress(ctx context.Context, config *model.Config, options release.Options, run func(context.Context, release.Options) (*release.Result, error)) error {
+	if !isDashboardWanted() {
+		_, err := run(ctx, options)
+		return err
+	}
+
+	board := newDashboard(os.Stdout, options.EngineVersions, previousDurations(config), logOptions.minLevel)
+	options.Logger = board.logger()
+	options.OnEvent = board.handleEvent
+
+	board.start()
+	_, err := run(ctx, options)
+	board.stop()
+	return err
 }
 
 func isEngineVersionsValid() bool {
 	if cmdInput.EngineVersions == "" {
-		fmt.Println("Missing required flag: --engine-versions is required.")
+		logger.Error("missing required flag: --engine-versions is required")
 		return false
 	}
 
 	validatorExpression := regexp.MustCompile(`^\d+\.\d+(,\d+\.\d+)*$`)
 	if !validatorExpression.MatchString(cmdInput.EngineVersions) {
-		fmt.Println("Spelling error in unreal engine versions. Must be MAJOR.MINOR e.g. 5.6, separated by commas.")
+		logger.Error("spelling error in unreal engine versions, must be MAJOR.MINOR e.g. 5.6, separated by commas", "value", cmdInput.EngineVersions)
 		return false
 	}
 
 	return true
 }
 
-func createAndValidateConfig(configPath string) (*model.Config, error) {
-	config, err := app.CreateConfig(configPath)
-	if err != nil || config == nil {
-		fmt.Print("Failed to load config file")
-		return nil, err
+func isPlatformsValid() bool {
+	if cmdInput.Platforms == "" {
+		return true
 	}
 
-	if !isConfigValid(config) {
-		fmt.Printf("The config file contains invalid path.")
-		return nil, errors.New("invalid config")
+	validatorExpression := regexp.MustCompile(`^[A-Za-z0-9]+(,[A-Za-z0-9]+)*$`)
+	if !validatorExpression.MatchString(cmdInput.Platforms) {
+		logger.Error("spelling error in target platforms, must be platform names like Win64, separated by commas", "value", cmdInput.Platforms)
+		return false
 	}
 
-	return config, nil
-}
-
-func isConfigValid(config *model.Config) bool {
-	// the bat file path is a relative path, so it cannot be validated here, only after the full path per version is assembled.
-	return app.IsPathExist(config.EngineBaseDirectory) &&
-		app.IsPathExist(config.OutputBaseDirectory) &&
-		!app.IsPathEqual(config.EngineBaseDirectory, config.OutputBaseDirectory) &&
-		isPluginLocationValid(config.PluginPath) &&
-		config.BuildScriptPath != ""
+	return true
 }
 
-func isPluginLocationValid(pluginPath string) bool {
-	if pluginPath == "" {
-		fmt.Println("--plugin-location is mandatory.")
+func isPackageModeValid() bool {
+	if cmdInput.VerifyBuild && !cmdInput.PackageOnly {
+		logger.Error("--verify-build can only be used together with --package-only")
 		return false
 	}
+	return true
+}
 
-	if !app.IsPathExist(pluginPath) {
-		fmt.Println("Plugin file not found:", pluginPath)
-		return false
+/*
+Loads the config and layers the profile, the environment and the flags over it,
+logging every problem of the config with its field and how to fix it.
+*/
+func createAndValidateConfig(configPath string) (*model.Config, error) {
+	config, _, err := app.LoadConfig(configPath, configLayers())
+	if err != nil {
+		logConfigError(configPath, err)
+		return nil, err
 	}
 
-	if !app.IsFile(pluginPath) {
-		fmt.Println("Plugin location needs to point to a file, not a directory.")
-		return false
+	if problems := app.ValidateConfig(config); len(problems) > 0 {
+		logConfigError(configPath, problems)
+		return nil, problems
 	}
+	return config, nil
+}
 
-	return true
+// a record per problem of the config, so they can all be fixed at once
+func logConfigError(configPath string, err error) {
+	var problems app.ValidationErrors
+	if !errors.As(err, &problems) {
+		logger.Error("failed to load config file", "path", configPath, "profile", cmdInput.Profile, "error", err)
+		return
+	}
+	for _, problem := range problems {
+		logger.Error("invalid config", "path", configPath, "field", problem.Field, "value", problem.Value, "problem", problem.Problem, "fix", problem.Fix)
+	}
 }
@@ -1,12 +1,23 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"unreal-plugin-release/app"
 	"unreal-plugin-release/model"
+	"unreal-plugin-release/release"
 )
 
 type engineVersionTestData struct {
@@ -14,6 +25,11 @@ type engineVersionTestData struct {
 	expected       bool
 }
 
+type platformsTestData struct {
+	platforms string
+	expected  bool
+}
+
 type validInputTestData struct {
 	engineVersions string
 	expected       bool
@@ -36,53 +52,6 @@ func TestIsInputValid(t *testing.T) {
 	}
 }
 
-func TestValidPluginLocation(t *testing.T) {
-	// when
-	actual := isPluginLocationValid(createPluginAtTempDir("MyPlugin.uplugin", t))
-
-	// then
-	if !actual {
-		t.Error("A valid plugin location must not be marked invalid.")
-	}
-}
-
-func TestInvalidPluginLocation(t *testing.T) {
-	// given
-	base := t.TempDir()
-	missingPath := filepath.Join(base, "MissingDirectory", "NoPlugin.uplugin")
-
-	// when
-	actual := isPluginLocationValid(missingPath)
-
-	// then
-	if actual {
-		t.Error("A missing plugin location must be invalid.")
-	}
-}
-
-func TestMissingPluginLocation(t *testing.T) {
-	// when
-	actual := isPluginLocationValid("")
-
-	// then
-	if actual {
-		t.Error("An empty plugin location must be invalid.")
-	}
-}
-
-func TestNotFilePluginLocation(t *testing.T) {
-	// given
-	dir := t.TempDir()
-
-	// when
-	actual := isPluginLocationValid(dir)
-
-	// then
-	if actual {
-		t.Error("A directory as plugin location must be invalid.")
-	}
-}
-
 func TestIsEngineVersionsValid(t *testing.T) {
 	for i, tt := range createEngineVersionsTestData() {
 		t.Run("EngineVersionTest #"+strconv.Itoa(i), func(t *testing.T) {
@@ -100,109 +69,22 @@ func TestIsEngineVersionsValid(t *testing.T) {
 	}
 }
 
-func TestValidPathsShouldMakeValidConfig(t *testing.T) {
-	// given
-	base := t.TempDir()
-	enginePath := filepath.Join(base, "Engine")
-	outputPath := filepath.Join(base, "Output")
-	if err := os.MkdirAll(enginePath, 0755); err != nil {
-		t.Fatal("Failed to create temp dir for Engine.")
-	}
-	if err := os.MkdirAll(outputPath, 0755); err != nil {
-		t.Fatal("Failed to create temp dir for Output.")
-	}
-	upluginPath := filepath.Join(enginePath, "MyPlugin.uplugin")
-	f, err := os.Create(upluginPath)
-	if err != nil {
-		t.Fatal("Failed to create uplugin.")
-	}
-	defer f.Close()
-	var config = model.Config{
-		EngineBaseDirectory: enginePath,
-		OutputBaseDirectory: outputPath,
-		BuildScriptPath:     "not empty",
-		PluginPath:          upluginPath,
-	}
-
-	// when
-	actual := isConfigValid(&config)
-
-	// then
-	if !actual {
-		t.Error("A valid configuration should not fail the validation.")
-	}
-}
-
-func TestMissingEngineBaseDirectoryShouldFailValidation(t *testing.T) {
-	// given
-	base := t.TempDir()
-	enginePath := filepath.Join(base, "Engine")
-	outputPath := filepath.Join(base, "Output")
-	if err := os.MkdirAll(outputPath, 0755); err != nil {
-		t.Fatal("Failed to create temp dir for Output.")
-	}
-	var config = model.Config{
-		EngineBaseDirectory: enginePath,
-		OutputBaseDirectory: outputPath,
-		BuildScriptPath:     "not empty",
-	}
-
-	// when
-	actual := isConfigValid(&config)
-
-	// then
-	if actual {
-		t.Error("A missing engine directory should fail the validation.")
-	}
-}
-
-func TestMissingOutputDirectoryShouldFailValidation(t *testing.T) {
-	// given
-	base := t.TempDir()
-	enginePath := filepath.Join(base, "Engine")
-	outputPath := filepath.Join(base, "Output")
-	if err := os.MkdirAll(enginePath, 0755); err != nil {
-		t.Fatal("Failed to create temp dir for Engine.")
-	}
-	var config = model.Config{
-		EngineBaseDirectory: enginePath,
-		OutputBaseDirectory: outputPath,
-		BuildScriptPath:     "not empty",
-	}
-
-	// when
-	actual := isConfigValid(&config)
-
-	// then
-	if actual {
-		t.Error("A missing output directory should fail the validation.")
-	}
-}
-
-func TestMissingBuildScriptPathShouldFailValidation(t *testing.T) {
-	// given
-	base := t.TempDir()
-	enginePath := filepath.Join(base, "Engine")
-	outputPath := filepath.Join(base, "Output")
-	if err := os.MkdirAll(enginePath, 0755); err != nil {
-		t.Fatal("Failed to create temp dir for Engine.")
-	}
-	if err := os.MkdirAll(outputPath, 0755); err != nil {
-		t.Fatal("Failed to create temp dir for Output.")
-	}
-	var config = model.Config{
-		EngineBaseDirectory: enginePath,
-		OutputBaseDirectory: outputPath,
-		BuildScriptPath:     "",
-	}
+func TestIsPlatformsValid(t *testing.T) {
+	for i, tt := range createPlatformsTestData() {
+		t.Run("PlatformsTest #"+strconv.Itoa(i), func(t *testing.T) {
+			// given
+			cmdInput.Platforms = tt.platforms
 
-	// when
-	actual := isConfigValid(&config)
+			// when
+			actual := isPlatformsValid()
 
-	// then
-	if actual {
-		t.Error("A missing build script path should fail the validation.")
+			// then
+			if actual != tt.expected {
+				t.Errorf("%q: expected %t, got %t", tt.platforms, tt.expected, actual)
+			}
+		})
 	}
+	cmdInput.Platforms = ""
 }
 
 func TestCreateAndValidateConfig(t *testing.T) {
@@ -243,7 +125,7 @@ func TestCreateAndValidateConfig(t *testing.T) {
 	actual, _ := createAndValidateConfig(configPath)
 
 	// then
-	if *actual != expected {
+	if !reflect.DeepEqual(*actual, expected) {
 		t.Errorf("Configs differ. Expected: %v, Actual: %v", expected, actual)
 	}
 }
@@ -323,19 +205,6 @@ func TestEngineBaseDirAndOutputDirMustDiffer(t *testing.T) {
 }
 
 // create data for tests
-func createPluginAtTempDir(pluginName string, t *testing.T) string {
-	t.Helper()
-
-	base := t.TempDir()
-	pluginFile := filepath.Join(base, pluginName)
-	file, err := os.Create(pluginFile)
-	if err != nil {
-		t.Fatalf("Failed to create temp dir %q", pluginFile)
-	}
-	file.Close()
-	return pluginFile
-}
-
 func createEngineVersionsTestData() []engineVersionTestData {
 	return []engineVersionTestData{
 		{
@@ -381,3 +250,167 @@ func createIsInputValidTestData() []validInputTestData {
 		},
 	}
 }
+
+func createPlatformsTestData() []platformsTestData {
+	return []platformsTestData{
+		{
+			platforms: "",
+			expected:  true,
+		},
+		{
+			platforms: "Win64",
+			expected:  true,
+		},
+		{
+			platforms: "Win64,Linux,Android",
+			expected:  true,
+		},
+		{
+			platforms: "Win64, Linux",
+			expected:  false,
+		},
+		{
+			platforms: "Win64,",
+			expected:  false,
+		},
+	}
+}
+
+func TestSetUpLoggerShouldApplyTheLogFlags(t *testing.T) {
+	defer func() {
+		logOptions.level, logOptions.format, logOptions.quiet = "info", "text", false
+		setUpLogger(rootCmd, nil)
+	}()
+
+	logOptions.level, logOptions.format, logOptions.quiet = "debug", "json", true
+	if err := setUpLogger(rootCmd, nil); err != nil {
+		t.Fatalf("Valid log flags should be accepted: %v", err)
+	}
+	if logger.Enabled(context.Background(), slog.LevelWarn) || !logger.Enabled(context.Background(), slog.LevelError) {
+		t.Error("--quiet should only let errors through, whatever the level.")
+	}
+
+	logOptions.level, logOptions.format, logOptions.quiet = "verbose", "text", false
+	if err := setUpLogger(rootCmd, nil); err == nil {
+		t.Error("An unknown log level should be rejected.")
+	}
+
+	logOptions.level, logOptions.format = "info", "xml"
+	if err := setUpLogger(rootCmd, nil); err == nil {
+		t.Error("An unknown log format should be rejected.")
+	}
+}
+
+func TestDashboardShouldShowARowPerVersion(t *testing.T) {
+	// given
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	expected := map[string]time.Duration{"5.4": 5 * time.Minute, "5.5": 4 * time.Minute}
+	underTest := newDashboard(&bytes.Buffer{}, []string{"5.3", "5.4", "5.5"}, expected, slog.LevelInfo)
+	underTest.now = func() time.Time { return clock }
+	batchLogger := underTest.logger()
+
+	// when
+	underTest.handleEvent(release.VersionStarted{Version: "5.3"})
+	underTest.handleEvent(release.StageStarted{Version: "5.3", Stage: "publish"})
+	clock = clock.Add(90 * time.Second)
+	underTest.handleEvent(release.VersionFinished{Version: "5.3"})
+	underTest.handleEvent(release.VersionStarted{Version: "5.4"})
+	underTest.handleEvent(release.StageStarted{Version: "5.4", Stage: "build"})
+	for i := 1; i <= 4; i++ {
+		batchLogger.With("version", "5.4").Info("UAT line "+strconv.Itoa(i), "subprocess", "uat", "stream", "stdout")
+	}
+	batchLogger.Info("building", "version", "5.4")
+	batchLogger.Warn("failed to delete", "path", "C:/Temp")
+	clock = clock.Add(2 * time.Minute)
+	lines := underTest.render()
+
+	// then
+	expectedLines := []string{
+		"UE 5.3    ✔ done     publish   1m30s",
+		"UE 5.4    ▶ running  build     2m0s  ETA 3m0s",
+		"           │ UAT line 2",
+		"           │ UAT line 3",
+		"           │ UAT line 4",
+		"UE 5.5    · pending            ~4m0s",
+		"WARN failed to delete path=C:/Temp",
+	}
+	if !reflect.DeepEqual(lines, expectedLines) {
+		t.Errorf("Expected the dashboard\n%s\ngot\n%s", strings.Join(expectedLines, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestAskScaffoldAnswersShouldPreferAnswersThenFlagsThenSuggestions(t *testing.T) {
+	// given
+	base := t.TempDir()
+	uplugin := filepath.Join(base, "MyProject", "Plugins", "MyPlugin", "MyPlugin.uplugin")
+	os.MkdirAll(filepath.Dir(uplugin), 0755)
+	os.WriteFile(uplugin, []byte(`{"FileVersion": 3}`), 0644)
+	docs := filepath.Join(base, "Manual.pdf")
+	initInput.outputDirectory = filepath.Join(base, "Out")
+	defer func() { initInput.outputDirectory = "" }()
+
+	// the plugin from the argument, the base directory cleared, the build script and output kept, then the docs
+	input := strings.NewReader("\n-\n\n\n" + docs + "\n/Documentation/Manual.pdf\n")
+	prompt := newPrompter(input, &bytes.Buffer{}, false)
+
+	// when
+	answers, err := askScaffoldAnswers(prompt, []string{uplugin})
+
+	// then
+	if err != nil {
+		t.Fatalf("The answers should have been collected: %v", err)
+	}
+	if answers.PluginPath != uplugin || answers.EngineBaseDirectory != "" || answers.OutputBaseDirectory != initInput.outputDirectory {
+		t.Errorf("Unexpected answers %+v", answers)
+	}
+	if answers.DocsPath != docs || answers.DocsPathInPlugin != "/Documentation/Manual.pdf" {
+		t.Errorf("The docs should be taken from the answers, got %+v", answers)
+	}
+}
+
+func TestAskScaffoldAnswersShouldNotAskWhenNonInteractive(t *testing.T) {
+	uplugin := filepath.Join(t.TempDir(), "MyProject", "Plugins", "MyPlugin", "MyPlugin.uplugin")
+	prompt := newPrompter(strings.NewReader(""), &bytes.Buffer{}, true)
+
+	answers, err := askScaffoldAnswers(prompt, []string{uplugin})
+
+	if err != nil {
+		t.Fatalf("Nothing should have been asked: %v", err)
+	}
+	if answers.OutputBaseDirectory != filepath.Join(filepath.Dir(filepath.Dir(filepath.Dir(filepath.Dir(uplugin)))), "Releases", "MyPlugin") {
+		t.Errorf("The suggested output directory should be used, got %q", answers.OutputBaseDirectory)
+	}
+}
+
+func TestConfigFlagsShouldOverrideTheConfigAndShowTheirSource(t *testing.T) {
+	// given
+	cmd := &cobra.Command{Use: "show"}
+	addConfigFlags(cmd)
+	defer func() { flagOverrides = map[string]string{} }()
+	if err := cmd.ParseFlags([]string{"--output-base-directory", "D:/CI/Releases"}); err != nil {
+		t.Fatalf("The generated flag should parse: %v", err)
+	}
+	config := &model.Config{OutputBaseDirectory: "D:/Releases", PluginPath: "MyPlugin.uplugin"}
+	if err := app.ApplyOverrides(config, flagOverrides, app.SourceFlag); err != nil {
+		t.Fatalf("The flag should apply: %v", err)
+	}
+	sources := map[string]app.ConfigSource{"outputBaseDirectory": app.SourceFlag, "pluginPath": app.SourceFile}
+	var out bytes.Buffer
+
+	// when
+	printEffectiveConfig(&out, config, sources)
+
+	// then
+	lines := map[string]string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			lines[fields[0]] = line
+		}
+	}
+	if !strings.Contains(lines["outputBaseDirectory"], `flag --output-base-directory  "D:/CI/Releases"`) {
+		t.Errorf("The flag should win and be shown as the source, got %q", lines["outputBaseDirectory"])
+	}
+	if !strings.Contains(lines["pluginPath"], "file") {
+		t.Errorf("The value from the file should be shown as such, got %q", lines["pluginPath"])
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package cmd
+
+import "os"
+
+// terminals other than the Windows console understand the escape sequences as they are
+func enableTerminalEscapes(file *os.File) bool {
+	return true
+}
+
+// left to COLUMNS, as the standard library can't ask the terminal itself
+func consoleWidth(file *os.File) int {
+	return 0
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	setConsoleMode                 = kernel32.NewProc("SetConsoleMode")
+	getConsoleScreenBufferInfoProc = kernel32.NewProc("GetConsoleScreenBufferInfo")
+)
+
+type consoleCoordinates struct {
+	x, y int16
+}
+
+type consoleScreenBufferInfo struct {
+	size              consoleCoordinates
+	cursorPosition    consoleCoordinates
+	attributes        uint16
+	left, top         int16
+	right, bottom     int16
+	maximumWindowSize consoleCoordinates
+}
+
+// switches the console to the escape sequences the dashboard moves the cursor with, the legacy console only has them when asked
+func enableTerminalEscapes(file *os.File) bool {
+	handle := syscall.Handle(file.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(handle, &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	result, _, _ := setConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing))
+	return result != 0
+}
+
+// the width of the console window, cmd.exe and PowerShell don't set COLUMNS
+func consoleWidth(file *os.File) int {
+	var info consoleScreenBufferInfo
+	result, _, _ := getConsoleScreenBufferInfoProc.Call(file.Fd(), uintptr(unsafe.Pointer(&info)))
+	if result == 0 {
+		return 0
+	}
+	return int(info.right-info.left) + 1
+}
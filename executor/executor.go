@@ -16,10 +16,13 @@ import (
 /*
 Creates the commands that are ran as subprocesses in the application.
 Different platforms like Mac or Linux need their own implementation.
+The application forwards the commands' output to its logger line by line, tagged with the subprocess it came from.
 */
 type SubprocessExecutor interface {
 	CreateZipCommand(sourceDir string) *exec.Cmd
-	CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string) *exec.Cmd
+	CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string, extraArgs []string) *exec.Cmd
+	CreateGitCommitCommand(repositoryDir string) *exec.Cmd
+	CreateHookCommand(command string) *exec.Cmd
 }
 
 /*
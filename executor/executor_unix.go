@@ -9,22 +9,32 @@ type UnixExecutor struct {
 
 /*
 Implement this method to call the unix version of Epic's RunUAT.bat, which builds the plugin for the release.
-To see the logs of the build script, don't forget to route os logs to that of this executable, e.g.
-
-	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
+The extra arguments, like -Rocket or the target platforms, need to be appended to the BuildPlugin arguments.
+The output of the command is forwarded to the logger by the caller, so leave Stdout and Stderr unset.
 */
-func (e UnixExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string) *exec.Cmd {
+func (e UnixExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string, extraArgs []string) *exec.Cmd {
 	panic("Unix executor for build script command is not implemented.")
 }
 
 /*
 Implement this method to zip the created plugin folder.
-To see the logs of the zip command, don't forget to route os logs to that of this executable, e.g.
-
-	zipCmd.Stdout = os.Stdout
-	zipCmd.Stderr = os.Stderr
+The output of the command is forwarded to the logger by the caller, so leave Stdout and Stderr unset.
 */
 func (e UnixExecutor) CreateZipCommand(sourceDir string) *exec.Cmd {
 	panic("Unix executor for zip command is not implemented.")
 }
+
+/*
+Creates the command that prints the short hash of the commit the repository is at.
+Git behaves the same on every platform, so this one needs no platform specific implementation.
+*/
+func (e UnixExecutor) CreateGitCommitCommand(repositoryDir string) *exec.Cmd {
+	return exec.Command("git", "-C", repositoryDir, "rev-parse", "--short", "HEAD")
+}
+
+/*
+Creates the command that runs a hook of the config through sh, so it can be a script or a whole command line.
+*/
+func (e UnixExecutor) CreateHookCommand(command string) *exec.Cmd {
+	return exec.Command("sh", "-c", command)
+}
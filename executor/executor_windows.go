@@ -2,7 +2,6 @@ package executor
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
 )
 
@@ -13,20 +12,17 @@ type WindowsExecutor struct {
 }
 
 /*
-Creates the command that calls the RunUAT.bat file.
+Creates the command that calls the RunUAT.bat file, with the version specific arguments appended to BuildPlugin's.
 */
-func (e WindowsExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string) *exec.Cmd {
+func (e WindowsExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string, extraArgs []string) *exec.Cmd {
 	args := []string{
 		"BuildPlugin",
 		"-Plugin=" + pluginLocation,
 		"-Package=" + outputDir,
-		"-Rocket",
 	}
+	args = append(args, extraArgs...)
 
-	buildCmd := exec.Command("cmd", append([]string{"/C", buildScriptPath}, args...)...)
-	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
-	return buildCmd
+	return exec.Command("cmd", append([]string{"/C", buildScriptPath}, args...)...)
 }
 
 /*
@@ -35,10 +31,20 @@ Creates the command that calls the zip command.
 func (e WindowsExecutor) CreateZipCommand(sourceDir string) *exec.Cmd {
 	// Example PowerShell command:
 	// Compress-Archive -Path "C:\MyFolder\*" -DestinationPath "C:\MyZip.zip" -Force
-	cmd := exec.Command("powershell", "-Command", fmt.Sprintf(`Compress-Archive -Path "%s\*" -DestinationPath "%s" -Force`, sourceDir, sourceDir+".zip"))
+	return exec.Command("powershell", "-Command", fmt.Sprintf(`Compress-Archive -Path "%s\*" -DestinationPath "%s" -Force`, sourceDir, sourceDir+".zip"))
+}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+/*
+Creates the command that prints the short hash of the commit the repository is at.
+Its output is read by the caller.
+*/
+func (e WindowsExecutor) CreateGitCommitCommand(repositoryDir string) *exec.Cmd {
+	return exec.Command("git", "-C", repositoryDir, "rev-parse", "--short", "HEAD")
+}
 
-	return cmd
+/*
+Creates the command that runs a hook of the config through cmd, so it can be a script or a whole command line.
+*/
+func (e WindowsExecutor) CreateHookCommand(command string) *exec.Cmd {
+	return exec.Command("cmd", "/C", command)
 }
@@ -4,3 +4,12 @@ package model
 const ConfigFile = "config.json"
 const ConfigDirectoryName = "Config"
 const PluginConfigurationIniFileName = "FilterPlugin.ini"
+const LogsDirectoryName = "Logs"
+const BuildReportFile = "build-report.json"
+const BuildDurationsFile = "build-durations.json"
+const StagingDirectoryName = ".staging"
+const OwnershipMarkerFile = ".unreal-plugin-release"
+const PublishedTagExtension = ".published"
+const ConfigSchemaFile = "config.schema.json"
+const OutputLockFile = ".unreal-plugin-release.lock"
+const BatchJournalFile = "batch-journal.json"
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// progress of a batch, reported as it happens to whoever runs it
+type Event interface {
+	isEvent()
+}
+
+// what kind of file an ArtifactWritten event is about
+type ArtifactKind string
+
+const (
+	ArtifactRelease  ArtifactKind = "release"
+	ArtifactArchive  ArtifactKind = "archive"
+	ArtifactChecksum ArtifactKind = "checksum"
+	ArtifactLog      ArtifactKind = "log"
+	ArtifactReport   ArtifactKind = "report"
+)
+
+// a version is about to go through the stages
+type VersionStarted struct {
+	Version   string
+	OutputDir string
+}
+
+// a version went through all of its stages, or failed in one of them
+type VersionFinished struct {
+	Version   string
+	OutputDir string
+	Err       error
+}
+
+// a stage of a version is about to run
+type StageStarted struct {
+	Version string
+	Stage   string
+}
+
+// a stage of a version is done, successfully if Err is nil
+type StageFinished struct {
+	Version  string
+	Stage    string
+	Duration time.Duration
+	Err      error
+}
+
+// a file or folder was written where it stays, the Version is empty for the batch's own files like the report
+type ArtifactWritten struct {
+	Version string
+	Kind    ArtifactKind
+	Path    string
+}
+
+func (VersionStarted) isEvent()  {}
+func (VersionFinished) isEvent() {}
+func (StageStarted) isEvent()    {}
+func (StageFinished) isEvent()   {}
+func (ArtifactWritten) isEvent() {}
@@ -1,16 +1,150 @@
 // DTOs and model structs go here that are used by other packages.
 package model
 
+import "encoding/json"
+
 // represents the json configuration file
 type Config struct {
-	EngineBaseDirectory string `json:"engineBaseDirectory"`
-	BuildScriptPath     string `json:"buildScriptPath"`
-	OutputBaseDirectory string `json:"outputBaseDirectory"`
-	PluginPath          string `json:"pluginPath"`
-	DocsPath            string `json:"docsPath"`
+	Schema                string                     `json:"$schema,omitempty"`
+	EngineBaseDirectory   string                     `json:"engineBaseDirectory"`
+	BuildScriptPath       string                     `json:"buildScriptPath"`
+	OutputBaseDirectory   string                     `json:"outputBaseDirectory"`
+	PluginPath            string                     `json:"pluginPath"`
+	DocsPath              string                     `json:"docsPath"`
+	Engines               map[string]string          `json:"engines,omitempty"`
+	LauncherInstalledPath string                     `json:"launcherInstalledPath,omitempty"`
+	OutputNameTemplate    string                     `json:"outputNameTemplate,omitempty"`
+	TargetPlatforms       []string                   `json:"targetPlatforms,omitempty"`
+	ExtraUatArgs          map[string][]string        `json:"extraUatArgs,omitempty"`
+	Retry                 *RetryConfig               `json:"retry,omitempty"`
+	Versions              map[string]VersionConfig   `json:"versions,omitempty"`
+	Stages                []StageConfig              `json:"stages,omitempty"`
+	Hooks                 *HooksConfig               `json:"hooks,omitempty"`
+	Retention             *RetentionConfig           `json:"retention,omitempty"`
+	Profiles              map[string]json.RawMessage `json:"profiles,omitempty"`
+}
+
+// settings that only apply to a single engine version, overriding the global ones
+type VersionConfig struct {
+	TargetPlatforms []string     `json:"targetPlatforms,omitempty"`
+	Retry           *RetryConfig `json:"retry,omitempty"`
+}
+
+// a step of the release pipeline, by its registered name, with the options of that stage
+type StageConfig struct {
+	Name    string          `json:"name"`
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// commands run at points of the batch, with its context in UPR_ environment variables
+type HooksConfig struct {
+	PreBatch    string `json:"preBatch,omitempty"`
+	PreVersion  string `json:"preVersion,omitempty"`
+	PostBuild   string `json:"postBuild,omitempty"`
+	PostArchive string `json:"postArchive,omitempty"`
+	PostBatch   string `json:"postBatch,omitempty"`
+	OnFailure   string `json:"onFailure,omitempty"`
+}
+
+// which old releases are removed from the output directory, by clean or after a batch
+type RetentionConfig struct {
+	KeepLast   int `json:"keepLast,omitempty"`
+	MaxAgeDays int `json:"maxAgeDays,omitempty"`
+	// lets maxAgeDays remove even the newest release of a version, when keepLast isn't set
+	RemoveLastOfVersion bool `json:"removeLastOfVersion,omitempty"`
+	AfterBatch          bool `json:"afterBatch,omitempty"`
+}
+
+// how many times a failed build is attempted, and which failures are worth another attempt
+type RetryConfig struct {
+	MaxAttempts       int      `json:"maxAttempts"`
+	BackoffSeconds    int      `json:"backoffSeconds"`
+	RetryablePatterns []string `json:"retryablePatterns,omitempty"`
+}
+
+// an engine installation that can build the plugin, and where it was found
+type EngineInstall struct {
+	Version         string
+	Root            string
+	BuildScriptPath string
+	Source          string
+}
+
+// the contents of an engine's Engine/Build/Build.version
+type EngineBuildVersion struct {
+	MajorVersion int    `json:"MajorVersion"`
+	MinorVersion int    `json:"MinorVersion"`
+	PatchVersion int    `json:"PatchVersion"`
+	Changelist   int    `json:"Changelist"`
+	BranchName   string `json:"BranchName"`
 }
 
 type CmdInput struct {
 	EngineVersions string
 	SkipDocs       bool
+	Platforms      string
+	Profile        string
+	PackageOnly    bool
+	VerifyBuild    bool
+	// continue the interrupted batch of the journal instead of starting over
+	Resume bool
+}
+
+// the values an outputNameTemplate can use
+type OutputNameData struct {
+	PluginName         string
+	PluginVersion      string
+	EngineVersion      string
+	EnginePatch        int
+	ExactEngineVersion string
+	Platforms          []string
+	Date               string
+	GitCommit          string
+	Profile            string
+}
+
+// summary of a batch, written next to the releases
+type BuildReport struct {
+	Versions []VersionReport `json:"versions"`
+}
+
+type VersionReport struct {
+	Version         string          `json:"version"`
+	OutputDir       string          `json:"outputDir"`
+	EngineVersion   string          `json:"engineVersion,omitempty"`
+	Changelist      int             `json:"changelist,omitempty"`
+	TargetPlatforms []string        `json:"targetPlatforms,omitempty"`
+	PackageOnly     bool            `json:"packageOnly,omitempty"`
+	ReusedBuildOf   string          `json:"reusedBuildOf,omitempty"`
+	DurationSeconds float64         `json:"durationSeconds,omitempty"`
+	Succeeded       bool            `json:"succeeded"`
+	Attempts        []AttemptReport `json:"attempts"`
+}
+
+// the progress of a batch, written as its versions and stages complete, so an interrupted batch can be resumed
+type BatchJournal struct {
+	Inputs   JournalInputs    `json:"inputs"`
+	Versions []JournalVersion `json:"versions"`
+	// the report of the batch so far, continued by the resumed batch
+	Report BuildReport `json:"report"`
+}
+
+// digests of what the batch was started with, a batch is only resumed with the same ones
+type JournalInputs struct {
+	Config  string `json:"config"`
+	Command string `json:"command"`
+	Plugin  string `json:"plugin"`
+}
+
+type JournalVersion struct {
+	Version         string   `json:"version"`
+	CompletedStages []string `json:"completedStages"`
+	Finished        bool     `json:"finished"`
+}
+
+type AttemptReport struct {
+	Number    int    `json:"number"`
+	LogPath   string `json:"logPath"`
+	Error     string `json:"error,omitempty"`
+	Retryable bool   `json:"retryable"`
 }
@@ -0,0 +1,196 @@
+/*
+Package release builds and packages an Unreal plugin for several engine versions, for Go programs embedding the tool.
+The command line tool is one of its consumers: it loads config.json, runs the batch and prints the events.
+*/
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"unreal-plugin-release/app"
+	"unreal-plugin-release/executor"
+	"unreal-plugin-release/model"
+)
+
+// the events of a batch, passed to Options.OnEvent as they happen
+type (
+	Event           = model.Event
+	VersionStarted  = model.VersionStarted
+	VersionFinished = model.VersionFinished
+	StageStarted    = model.StageStarted
+	StageFinished   = model.StageFinished
+	ArtifactWritten = model.ArtifactWritten
+	ArtifactKind    = model.ArtifactKind
+)
+
+// the file operations of a batch, see app.FileSystem
+type FileSystem = app.FileSystem
+
+// a step of the release pipeline and how it's created from its options in the config, see app.Stage
+type (
+	Stage        = app.Stage
+	StageFactory = app.StageFactory
+)
+
+// another run holds the lock of the output directory, see app.LockedError
+type LockedError = app.LockedError
+
+// what to release and how
+type Options struct {
+	// the configuration, like config.json, it's not modified
+	Config *model.Config
+	// whether Config is already effective, with the profile, the overrides and the paths applied, like app.LoadConfig gives it.
+	// Profile then only names the profile in the output names, and Overrides and ConfigDir are not used
+	Resolved bool
+	// the profile of the config to apply, if any
+	Profile string
+	// config fields by json name, applied over the profile, see app.ApplyOverrides
+	Overrides map[string]string
+	// the folder relative paths of the config are resolved against, the working directory if empty
+	ConfigDir string
+	// the engine versions to release for, e.g. 5.3 and 5.4
+	EngineVersions []string
+	// the target platforms, overriding the ones in the config
+	Platforms   []string
+	SkipDocs    bool
+	PackageOnly bool
+	VerifyBuild bool
+	// continue the batch interrupted in the output directory, at its first incomplete version and stage
+	Resume bool
+	// the executable next to which FilterPlugin.ini is, the running one if empty
+	ExecPath string
+
+	// runs UAT, the zip and the hooks, the one for the current OS if nil
+	Executor executor.SubprocessExecutor
+	// the OS file system if nil. The batch finds the engines and reads their Build.version through it too,
+	// only the commands of the executor work on the disk
+	FileSystem FileSystem
+	// stages the config may list besides the built-in ones, by name, replacing a built-in stage of the same name
+	Stages map[string]StageFactory
+	// receives the progress as structured records, including the output of UAT, discarded if nil
+	Logger *slog.Logger
+	// called with every event, in order, on the goroutine calling Run
+	OnEvent func(Event)
+}
+
+// what a batch did
+type Result struct {
+	Report model.BuildReport
+}
+
+/*
+Releases the plugin for every engine version of the options, one after the other, stopping at the first failure.
+Cancelling the context stops the running build and cleans up its staging area.
+The result holds what happened up to that point, even when an error is returned.
+*/
+func Run(ctx context.Context, options Options) (*Result, error) {
+	builder, input, execPath, err := prepare(options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = builder.BuildPluginsForSelectedVersions(ctx, input, execPath)
+	return &Result{Report: builder.Report()}, err
+}
+
+/*
+Repeats the stages after the build on the releases already in the output directory, without building them again.
+Versions without a usable release are skipped, an error is returned if none could be processed.
+*/
+func PostProcess(ctx context.Context, options Options) (*Result, error) {
+	builder, input, execPath, err := prepare(options)
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := builder.PostProcessExistingReleases(ctx, input, execPath)
+	if err == nil && processed == 0 {
+		err = errors.New("no release found to post-process")
+	}
+	return &Result{Report: builder.Report()}, err
+}
+
+func prepare(options Options) (*app.PluginBuilder, model.CmdInput, string, error) {
+	if options.Config == nil {
+		return nil, model.CmdInput{}, "", errors.New("no config given")
+	}
+	if len(options.EngineVersions) == 0 {
+		return nil, model.CmdInput{}, "", errors.New("no engine versions given")
+	}
+
+	config, err := effectiveConfig(options)
+	if err != nil {
+		return nil, model.CmdInput{}, "", err
+	}
+	// the paths are left to the file system of the options, they are checked as the batch uses them
+	if problems := app.ValidateSettingsWithStages(config, options.Stages); len(problems) > 0 {
+		return nil, model.CmdInput{}, "", problems
+	}
+
+	execPath := options.ExecPath
+	if execPath == "" {
+		if execPath, err = os.Executable(); err != nil {
+			return nil, model.CmdInput{}, "", fmt.Errorf("failed to find the executable: %w", err)
+		}
+	}
+
+	runner := options.Executor
+	if runner == nil {
+		runner = executor.NewExecutor()
+	}
+	logger := options.Logger
+	if logger == nil {
+		logger = app.NewDiscardLogger()
+	}
+
+	builder := app.NewPluginBuilderWithOptions(config, runner, app.BuilderOptions{
+		FileSystem: options.FileSystem,
+		Logger:     logger,
+		OnEvent:    options.OnEvent,
+		Stages:     options.Stages,
+	})
+	input := model.CmdInput{
+		EngineVersions: strings.Join(options.EngineVersions, ","),
+		SkipDocs:       options.SkipDocs,
+		Platforms:      strings.Join(options.Platforms, ","),
+		Profile:        options.Profile,
+		PackageOnly:    options.PackageOnly,
+		VerifyBuild:    options.VerifyBuild,
+		Resume:         options.Resume,
+	}
+	return builder, input, execPath, nil
+}
+
+// applies the profile and the overrides and resolves the paths on a copy, so the caller's config stays as it was.
+// A resolved config is only copied, applying them again would expand the paths twice.
+func effectiveConfig(options Options) (*model.Config, error) {
+	data, err := json.Marshal(options.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy config: %w", err)
+	}
+
+	copied := &model.Config{}
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, fmt.Errorf("failed to copy config: %w", err)
+	}
+	if options.Resolved {
+		return copied, nil
+	}
+
+	if err := app.ApplyProfile(copied, options.Profile); err != nil {
+		return nil, err
+	}
+	if err := app.ApplyOverrides(copied, options.Overrides, app.SourceFlag); err != nil {
+		return nil, err
+	}
+	if err := app.ResolveConfigPaths(copied, options.ConfigDir); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
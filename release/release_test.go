@@ -0,0 +1,302 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"unreal-plugin-release/app"
+	"unreal-plugin-release/model"
+)
+
+// builds by creating the folders UAT would, and zips by creating an empty archive
+type fakeExecutor struct {
+}
+
+func (fakeExecutor) CreateBuilderCommand(buildScriptPath string, pluginLocation string, outputDir string, extraArgs []string) *exec.Cmd {
+	if err := os.MkdirAll(filepath.Join(outputDir, "Source"), 0755); err != nil {
+		panic("Failed to write directory")
+	}
+	descriptor, err := os.ReadFile(pluginLocation)
+	if err != nil {
+		panic("Failed to read the descriptor")
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, filepath.Base(pluginLocation)), descriptor, 0644); err != nil {
+		panic("Failed to write the descriptor")
+	}
+	return emptyCommand()
+}
+
+func (fakeExecutor) CreateZipCommand(sourceDir string) *exec.Cmd {
+	if err := os.WriteFile(sourceDir+".zip", nil, 0644); err != nil {
+		panic("Couldn't create fake zip file")
+	}
+	return emptyCommand()
+}
+
+func (fakeExecutor) CreateGitCommitCommand(repositoryDir string) *exec.Cmd {
+	return emptyCommand()
+}
+
+func (fakeExecutor) CreateHookCommand(command string) *exec.Cmd {
+	return emptyCommand()
+}
+
+// a stage of the embedding program, recording the versions it ran for
+type recordingStage struct {
+	ran *[]string
+}
+
+func (recordingStage) Name() string {
+	return "record"
+}
+
+func (s recordingStage) Run(ctx context.Context, release *app.Release) error {
+	*s.ran = append(*s.ran, release.Version)
+	return nil
+}
+
+func emptyCommand() *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/c", "rem")
+	}
+	return exec.Command("true")
+}
+
+func TestRunShouldEmitEventsInOrder(t *testing.T) {
+	// given
+	config, execPath := writeProject("5.4", t)
+	var events []Event
+
+	// when
+	result, err := Run(context.Background(), Options{
+		Config:         config,
+		EngineVersions: []string{"5.4"},
+		SkipDocs:       true,
+		ExecPath:       execPath,
+		Executor:       fakeExecutor{},
+		OnEvent:        func(event Event) { events = append(events, event) },
+	})
+
+	// then
+	if err != nil {
+		t.Fatalf("The batch should have succeeded: %v", err)
+	}
+	if len(result.Report.Versions) != 1 || !result.Report.Versions[0].Succeeded {
+		t.Errorf("The report should hold the successful version, got %+v", result.Report.Versions)
+	}
+
+	if started, ok := events[0].(VersionStarted); !ok || started.Version != "5.4" {
+		t.Errorf("The first event should start the version, got %#v", events[0])
+	}
+
+	var stages []string
+	var published string
+	var finished *VersionFinished
+	for _, event := range events {
+		switch e := event.(type) {
+		case VersionFinished:
+			finished = &e
+		case StageFinished:
+			if e.Err != nil {
+				t.Errorf("Stage %s should have succeeded: %v", e.Stage, e.Err)
+			}
+			stages = append(stages, e.Stage)
+		case ArtifactWritten:
+			if e.Kind == model.ArtifactRelease {
+				published = e.Path
+			}
+		}
+	}
+	expectedStages := []string{"build", "clean", "stamp", "docs", "archive", "checksum", "publish"}
+	if len(stages) != len(expectedStages) {
+		t.Fatalf("Expected the stages %v, got %v", expectedStages, stages)
+	}
+	for i := range expectedStages {
+		if stages[i] != expectedStages[i] {
+			t.Errorf("Expected the stages %v, got %v", expectedStages, stages)
+			break
+		}
+	}
+	if published != filepath.Join(config.OutputBaseDirectory, "MyPlugin_5.4") {
+		t.Errorf("The release should have been published to the output directory, got %q", published)
+	}
+	if finished == nil || finished.Err != nil {
+		t.Errorf("The version should have finished successfully, got %#v", finished)
+	}
+}
+
+func TestRunShouldStopWhenCancelled(t *testing.T) {
+	// given
+	config, execPath := writeProject("5.4", t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// when
+	_, err := Run(ctx, Options{
+		Config:         config,
+		EngineVersions: []string{"5.4"},
+		SkipDocs:       true,
+		ExecPath:       execPath,
+		Executor:       fakeExecutor{},
+	})
+
+	// then
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("A cancelled batch should return context.Canceled, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(config.OutputBaseDirectory, "MyPlugin_5.4")); statErr == nil {
+		t.Error("Nothing should be published when the batch is cancelled.")
+	}
+}
+
+func TestRunShouldRequireConfigAndVersions(t *testing.T) {
+	if _, err := Run(context.Background(), Options{EngineVersions: []string{"5.4"}}); err == nil {
+		t.Error("Running without a config should fail.")
+	}
+	if _, err := Run(context.Background(), Options{Config: &model.Config{}}); err == nil {
+		t.Error("Running without engine versions should fail.")
+	}
+}
+
+func TestRunShouldRunTheStagesOfTheOptions(t *testing.T) {
+	// given
+	config, execPath := writeProject("5.4", t)
+	config.Stages = []model.StageConfig{{Name: "build"}, {Name: "record"}, {Name: "publish"}}
+	var ran []string
+	options := Options{
+		Config:         config,
+		EngineVersions: []string{"5.4"},
+		SkipDocs:       true,
+		ExecPath:       execPath,
+		Executor:       fakeExecutor{},
+	}
+
+	// when
+	_, withoutStage := Run(context.Background(), options)
+	options.Stages = map[string]StageFactory{"record": func(pb *app.PluginBuilder, raw json.RawMessage) (Stage, error) {
+		return recordingStage{ran: &ran}, nil
+	}}
+	_, err := Run(context.Background(), options)
+
+	// then
+	if withoutStage == nil {
+		t.Error("A stage not given in the options should be unknown.")
+	}
+	if err != nil {
+		t.Fatalf("The batch should have succeeded: %v", err)
+	}
+	if !slices.Equal(ran, []string{"5.4"}) {
+		t.Errorf("The stage of the options should have run, ran %v", ran)
+	}
+}
+
+func TestRunShouldNotModifyTheConfig(t *testing.T) {
+	// given
+	config, execPath := writeProject("5.4", t)
+	config.Profiles = map[string]json.RawMessage{"ci": json.RawMessage(`{"outputNameTemplate": "{{.PluginName}}-ci-{{.EngineVersion}}"}`)}
+
+	// when
+	_, err := Run(context.Background(), Options{
+		Config:         config,
+		Profile:        "ci",
+		EngineVersions: []string{"5.4"},
+		SkipDocs:       true,
+		ExecPath:       execPath,
+		Executor:       fakeExecutor{},
+	})
+
+	// then
+	if err != nil {
+		t.Fatalf("The batch should have succeeded: %v", err)
+	}
+	if config.OutputNameTemplate != "" {
+		t.Errorf("The profile should be applied on a copy, the config has %q", config.OutputNameTemplate)
+	}
+	if _, statErr := os.Stat(filepath.Join(config.OutputBaseDirectory, "MyPlugin-ci-5.4")); statErr != nil {
+		t.Error("The release should be named by the profile's template.")
+	}
+}
+
+func TestRunShouldApplyTheOverridesOverTheProfile(t *testing.T) {
+	// given
+	config, execPath := writeProject("5.4", t)
+	config.Profiles = map[string]json.RawMessage{"ci": json.RawMessage(`{"outputNameTemplate": "{{.PluginName}}-ci-{{.EngineVersion}}"}`)}
+
+	// when
+	_, err := Run(context.Background(), Options{
+		Config:         config,
+		Profile:        "ci",
+		Overrides:      map[string]string{"outputNameTemplate": "{{.PluginName}}-override-{{.EngineVersion}}"},
+		EngineVersions: []string{"5.4"},
+		SkipDocs:       true,
+		ExecPath:       execPath,
+		Executor:       fakeExecutor{},
+	})
+
+	// then
+	if err != nil {
+		t.Fatalf("The batch should have succeeded: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(config.OutputBaseDirectory, "MyPlugin-override-5.4")); statErr != nil {
+		t.Error("The release should be named by the overridden template.")
+	}
+}
+
+func TestRunShouldNotApplyTheProfileToAResolvedConfig(t *testing.T) {
+	// given
+	config, execPath := writeProject("5.4", t)
+	config.Profiles = map[string]json.RawMessage{"ci": json.RawMessage(`{"outputNameTemplate": "{{.PluginName}}-ci-{{.EngineVersion}}"}`)}
+	// as loaded with the profile, then a flag overriding it
+	config.OutputNameTemplate = "{{.PluginName}}-{{.Profile}}-flag-{{.EngineVersion}}"
+
+	// when
+	_, err := Run(context.Background(), Options{
+		Config:         config,
+		Resolved:       true,
+		Profile:        "ci",
+		EngineVersions: []string{"5.4"},
+		SkipDocs:       true,
+		ExecPath:       execPath,
+		Executor:       fakeExecutor{},
+	})
+
+	// then
+	if err != nil {
+		t.Fatalf("The batch should have succeeded: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(config.OutputBaseDirectory, "MyPlugin-ci-flag-5.4")); statErr != nil {
+		t.Error("The release should be named by the resolved template, with the name of the profile.")
+	}
+}
+
+// an engine with its build script, a plugin and an output folder, in a temporary directory
+func writeProject(version string, t *testing.T) (*model.Config, string) {
+	t.Helper()
+	base := t.TempDir()
+	for _, dir := range []string{filepath.Join("Engine", "UE_"+version), "Plugin", "Output"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0755); err != nil {
+			t.Fatalf("Failed to create directory %q", dir)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(base, "Engine", "UE_"+version, "RunUAT.bat"), nil, 0755); err != nil {
+		t.Fatal("Failed to write the build script")
+	}
+	uplugin := filepath.Join(base, "Plugin", "MyPlugin.uplugin")
+	if err := os.WriteFile(uplugin, []byte(`{"FileVersion": 3, "VersionName": "1.0", "Modules": [{"Name": "MyPlugin"}]}`), 0644); err != nil {
+		t.Fatal("Failed to write the descriptor")
+	}
+
+	return &model.Config{
+		EngineBaseDirectory: filepath.Join(base, "Engine"),
+		BuildScriptPath:     "RunUAT.bat",
+		OutputBaseDirectory: filepath.Join(base, "Output"),
+		PluginPath:          uplugin,
+	}, filepath.Join(base, "script.exe")
+}